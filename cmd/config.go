@@ -1,6 +1,14 @@
 package cmd
 
-import "github.com/spf13/pflag"
+import (
+	"fmt"
+	"time"
+
+	"github.com/lfordyce/tiger/internal/domain"
+	"github.com/lfordyce/tiger/pkg/postgres"
+	"github.com/lfordyce/tiger/pkg/queue"
+	"github.com/spf13/pflag"
+)
 
 // Config ...
 type Config struct {
@@ -17,3 +25,309 @@ func getConfig(flags *pflag.FlagSet) (Config, error) {
 		Workers: w,
 	}, nil
 }
+
+// getConnMode reads the conn-mode flag.
+func getConnMode(flags *pflag.FlagSet) (postgres.ConnMode, error) {
+	name, err := flags.GetString("conn-mode")
+	if err != nil {
+		return "", err
+	}
+	return postgres.ParseConnMode(name)
+}
+
+// queueFlagSet contains the flags of the pending job queue of the dispatcher.
+func queueFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.Int("queue-size", queue.DefaultCapacity, "Maximum number of requests waiting for a worker")
+	flags.String("queue-policy", string(queue.PolicyBlock), "What happens to a request when the queue is full, "+
+		"possible values are block (wait for a worker),drop-oldest (evict the longest waiting request),"+
+		"reject (skip the new request)")
+	return flags
+}
+
+// getQueueOptions reads the queue-size and queue-policy flags.
+func getQueueOptions(flags *pflag.FlagSet) (queue.Options, error) {
+	size, err := flags.GetInt("queue-size")
+	if err != nil {
+		return queue.Options{}, err
+	}
+	if size < 1 {
+		return queue.Options{}, fmt.Errorf("queue-size must be at least 1, got %d", size)
+	}
+	name, err := flags.GetString("queue-policy")
+	if err != nil {
+		return queue.Options{}, err
+	}
+	policy, err := queue.ParsePolicy(name)
+	if err != nil {
+		return queue.Options{}, err
+	}
+	return queue.Options{Capacity: size, Policy: policy}, nil
+}
+
+// csvFlagSet contains the flags describing the layout of an input csv file. They are shared by every
+// sub-command that reads query parameter files.
+func csvFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.String("csv-host-hdr", "hostname", "The name of the CSV host id field")
+	flags.String("csv-start-hdr", "start_time", "The name of the CSV start time field")
+	flags.String("csv-end-hdr", "end_time", "The name of the CSV end time field")
+	flags.AddFlagSet(timestampFlagSet())
+	return flags
+}
+
+// timestampFlagSet contains the flags describing how csv timestamp fields are parsed.
+func timestampFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.StringArray("csv-ts-fmt", []string{"2006-01-02 15:04:05"}, "The go timestamp format of the CSV "+
+		"timestamp field, or one of the presets rfc3339, iso8601, unix and unixms. Repeat for fallback formats")
+	flags.String("csv-tz", "UTC", "The time zone of CSV timestamps that carry no zone offset, e.g. America/New_York")
+	return flags
+}
+
+// postgresFlagSet contains the flags needed to connect to the benchmark database.
+func postgresFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.String("user", "postgres", "Postgres user")
+	flags.String("password", "password", "Postgres password")
+	flags.String("host", "localhost", "Postgres hostname")
+	flags.Uint16("port", 5432, "Postgres port")
+	flags.String("database", "homework", "Postgres database name")
+	return flags
+}
+
+// execFlagSet contains the flags controlling how benchmark queries are sent to the database.
+func execFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.String("exec-mode", string(postgres.ExecModeCacheStatement), "How queries are sent to the database, possible values are "+
+		"prepare,describe,simple,cache-statement")
+	flags.String("conn-mode", string(postgres.ConnModePool), "How workers share database connections, possible "+
+		"values are pool,per-worker")
+	flags.Bool("prepare", false, "Prepare the benchmark query once per connection")
+	flags.Int32("pool-max-conns", 0, "Maximum size of the connection pool, defaults to the number of workers")
+	flags.Int32("pool-min-conns", 0, "Minimum size of the connection pool, defaults to the maximum size, 0 lets the pool start empty")
+	return flags
+}
+
+// runOptions are the options of the run sub-command read from its flags.
+type runOptions struct {
+	Config
+	stages []stage
+	// rate paces the requests, 0 hands them over as fast as the queue accepts them
+	rate     float64
+	pgconn   *postgres.DBDetails
+	connMode postgres.ConnMode
+	queue    queue.Options
+	csv      *domain.QueryFormatProcess
+	targets  []postgres.Target
+	order    string
+	matrix   domain.Matrix
+	// groupings are the dimensions of every breakdown table
+	groupings [][]dimension
+	stats     statsOptions
+	// output leaves maxWidth at 0 when the tables are as wide as the terminal
+	output      outputOptions
+	outliers    string
+	interval    time.Duration
+	intervalOut string
+	htmlReport  string
+}
+
+// getRunOptions reads and validates the flags of the run sub-command.
+func getRunOptions(flags *pflag.FlagSet) (runOptions, error) {
+	config, err := getConfig(flags)
+	if err != nil {
+		return runOptions{}, err
+	}
+	opts := runOptions{Config: config}
+
+	stagesSpec, err := flags.GetString("stages")
+	if err != nil {
+		return runOptions{}, err
+	}
+	if opts.stages, err = parseStages(stagesSpec); err != nil {
+		return runOptions{}, err
+	}
+	if len(opts.stages) > 0 {
+		if flags.Changed("workers") {
+			return runOptions{}, fmt.Errorf("--workers can't be combined with --stages, the stages set the number of workers")
+		}
+		// workers and connections are sized for the busiest stage
+		opts.Workers = maxStageWorkers(opts.stages)
+	}
+	if opts.rate, err = flags.GetFloat64("rate"); err != nil {
+		return runOptions{}, err
+	}
+	if opts.rate < 0 {
+		return runOptions{}, fmt.Errorf("rate must be at least 0, got %v", opts.rate)
+	}
+
+	if opts.pgconn, err = postgres.GetConfig(flags); err != nil {
+		return runOptions{}, fmt.Errorf("failed to parse postgres config cli flags: %w", err)
+	}
+	if opts.pgconn.Exec, err = postgres.GetExecConfig(flags); err != nil {
+		return runOptions{}, fmt.Errorf("failed to parse exec config cli flags: %w", err)
+	}
+	if opts.pgconn.Pool, err = postgres.GetPoolConfig(flags, opts.Workers); err != nil {
+		return runOptions{}, fmt.Errorf("failed to parse pool config cli flags: %w", err)
+	}
+	if opts.connMode, err = getConnMode(flags); err != nil {
+		return runOptions{}, err
+	}
+	if opts.queue, err = getQueueOptions(flags); err != nil {
+		return runOptions{}, err
+	}
+	if opts.csv, err = domain.GetCsvConfig(flags); err != nil {
+		return runOptions{}, fmt.Errorf("failed to parse csv config cli flags: %w", err)
+	}
+
+	if opts.targets, err = postgres.GetTargets(flags, opts.pgconn); err != nil {
+		return runOptions{}, fmt.Errorf("failed to parse target cli flags: %w", err)
+	}
+	if opts.order, err = flags.GetString("target-order"); err != nil {
+		return runOptions{}, err
+	}
+	if opts.order != targetOrderInterleaved && opts.order != targetOrderPasses {
+		return runOptions{}, fmt.Errorf("unknown target order '%s', possible values are interleaved,passes", opts.order)
+	}
+	if opts.order == targetOrderPasses && len(opts.stages) > 0 {
+		return runOptions{}, fmt.Errorf("--stages can't be combined with --target-order %s", targetOrderPasses)
+	}
+
+	matrixSpecs, err := flags.GetStringArray("matrix")
+	if err != nil {
+		return runOptions{}, err
+	}
+	if opts.matrix, err = domain.ParseMatrix(matrixSpecs); err != nil {
+		return runOptions{}, fmt.Errorf("failed to parse matrix cli flags: %w", err)
+	}
+	for _, cell := range opts.matrix.Cells() {
+		if err := postgres.CheckParams(cell); err != nil {
+			return runOptions{}, fmt.Errorf("invalid matrix: %w", err)
+		}
+	}
+
+	groupBy, err := flags.GetStringArray("group-by")
+	if err != nil {
+		return runOptions{}, err
+	}
+	if opts.groupings, err = getGroupings(groupBy); err != nil {
+		return runOptions{}, err
+	}
+	if !opts.matrix.Empty() && !hasGrouping(opts.groupings, []dimension{cellDimension}) {
+		// statistics by matrix cell are always reported when benchmarking query variants
+		opts.groupings = append(opts.groupings, []dimension{cellDimension})
+	}
+	if opts.stats, err = getStatsOptions(flags); err != nil {
+		return runOptions{}, err
+	}
+	if err := validateSort(opts.groupings, opts.stats); err != nil {
+		return runOptions{}, err
+	}
+	if opts.output, err = getOutputOptions(flags); err != nil {
+		return runOptions{}, err
+	}
+
+	if opts.outliers, err = flags.GetString("outliers"); err != nil {
+		return runOptions{}, err
+	}
+	if err := validateOutliers(opts.outliers); err != nil {
+		return runOptions{}, err
+	}
+	if opts.interval, err = flags.GetDuration("interval"); err != nil {
+		return runOptions{}, err
+	}
+	if opts.intervalOut, err = flags.GetString("interval-out"); err != nil {
+		return runOptions{}, err
+	}
+	if opts.interval < 0 {
+		return runOptions{}, fmt.Errorf("interval must not be negative, got %s", opts.interval)
+	}
+	if opts.intervalOut != "" && opts.interval == 0 {
+		return runOptions{}, fmt.Errorf("--interval-out requires an --interval")
+	}
+	if opts.htmlReport, err = flags.GetString("html-report"); err != nil {
+		return runOptions{}, err
+	}
+	return opts, nil
+}
+
+// getStatsOptions reads the flags adding optional figures to the statistics tables.
+func getStatsOptions(flags *pflag.FlagSet) (statsOptions, error) {
+	ci, err := flags.GetFloat64("ci")
+	if err != nil {
+		return statsOptions{}, err
+	}
+	if ci < 0 || ci >= 100 {
+		return statsOptions{}, fmt.Errorf("confidence level must be between 0 and 100, got %v", ci)
+	}
+	trimSpec, err := flags.GetString("trim")
+	if err != nil {
+		return statsOptions{}, err
+	}
+	trim, err := parseTrim(trimSpec)
+	if err != nil {
+		return statsOptions{}, err
+	}
+	trimMode, err := flags.GetString("trim-mode")
+	if err != nil {
+		return statsOptions{}, err
+	}
+	winsorize, err := parseTrimMode(trimMode)
+	if err != nil {
+		return statsOptions{}, err
+	}
+	sortSpec, err := flags.GetString("sort")
+	if err != nil {
+		return statsOptions{}, err
+	}
+	warning, err := flags.GetDuration("warn-threshold")
+	if err != nil {
+		return statsOptions{}, err
+	}
+	critical, err := flags.GetDuration("crit-threshold")
+	if err != nil {
+		return statsOptions{}, err
+	}
+	if warning > 0 && critical > 0 && critical < warning {
+		return statsOptions{}, fmt.Errorf("crit-threshold %s must not be below warn-threshold %s", critical, warning)
+	}
+	return statsOptions{
+		ci: ci, trim: trim, winsorize: winsorize, sort: sortSpec,
+		thresholds: thresholds{warning: warning, critical: critical},
+	}, nil
+}
+
+// getOutputOptions reads the flags laying out the result tables. Colours depend on the terminal and are left to
+// the caller.
+func getOutputOptions(flags *pflag.FlagSet) (outputOptions, error) {
+	summaryFormat, err := flags.GetString("summary-format")
+	if err != nil {
+		return outputOptions{}, err
+	}
+	if err := validateSummaryFormat(summaryFormat); err != nil {
+		return outputOptions{}, err
+	}
+	maxWidth, err := flags.GetInt("max-width")
+	if err != nil {
+		return outputOptions{}, err
+	}
+	overflowSpec, err := flags.GetString("overflow")
+	if err != nil {
+		return outputOptions{}, err
+	}
+	overflow, err := parseOverflow(overflowSpec)
+	if err != nil {
+		return outputOptions{}, err
+	}
+	hidden, err := flags.GetStringSlice("hide-columns")
+	if err != nil {
+		return outputOptions{}, err
+	}
+	return outputOptions{format: summaryFormat, maxWidth: maxWidth, overflow: overflow, hidden: hidden}, nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lfordyce/tiger/pkg/queue"
+)
+
+func TestGetRunOptions(t *testing.T) {
+	cases := [...]struct {
+		desc    string
+		args    []string
+		check   func(t *testing.T, opts runOptions)
+		wantErr string
+	}{
+		{
+			desc: "defaults",
+			check: func(t *testing.T, opts runOptions) {
+				assert.Equal(t, 3, opts.Workers)
+				assert.Empty(t, opts.stages)
+				assert.Zero(t, opts.rate)
+				assert.Equal(t, int32(3), opts.pgconn.Pool.MaxConns)
+				assert.Equal(t, queue.Options{Capacity: queue.DefaultCapacity, Policy: queue.PolicyBlock}, opts.queue)
+				assert.Equal(t, targetOrderInterleaved, opts.order)
+				require.Len(t, opts.targets, 1)
+				require.Len(t, opts.groupings, 1)
+				assert.Equal(t, []string{"hostname"}, names(opts.groupings[0]))
+				assert.Equal(t, statsOptions{}, opts.stats)
+				assert.Zero(t, opts.output.maxWidth, "the terminal width is left to the run")
+				assert.Equal(t, outliersNone, opts.outliers)
+			},
+		},
+		{
+			desc: "stages size the workers for the busiest stage",
+			args: []string{"--stages", "10s:4,10s:8,10s:0"},
+			check: func(t *testing.T, opts runOptions) {
+				assert.Equal(t, 8, opts.Workers)
+				assert.Equal(t, int32(8), opts.pgconn.Pool.MaxConns)
+				assert.Len(t, opts.stages, 3)
+			},
+		},
+		{
+			desc:    "stages with workers",
+			args:    []string{"--stages", "10s:4", "--workers", "2"},
+			wantErr: "--workers can't be combined with --stages",
+		},
+		{
+			desc:    "stages with passes",
+			args:    []string{"--stages", "10s:4", "--target-order", "passes"},
+			wantErr: "--stages can't be combined with --target-order passes",
+		},
+		{desc: "negative rate", args: []string{"--rate", "-1"}, wantErr: "rate must be at least 0"},
+		{desc: "unknown target order", args: []string{"--target-order", "random"}, wantErr: "unknown target order"},
+		{desc: "invalid matrix", args: []string{"--matrix", "bucket"}, wantErr: "failed to parse matrix cli flags"},
+		{
+			desc: "matrix adds the cell statistics",
+			args: []string{"--matrix", "bucket=1m,5m"},
+			check: func(t *testing.T, opts runOptions) {
+				require.Len(t, opts.groupings, 2)
+				assert.Equal(t, []string{"cell"}, names(opts.groupings[1]))
+			},
+		},
+		{
+			desc: "matrix with cell statistics requested",
+			args: []string{"--matrix", "bucket=1m,5m", "--group-by", "cell", "--group-by", "hostname"},
+			check: func(t *testing.T, opts runOptions) {
+				require.Len(t, opts.groupings, 2)
+				assert.Equal(t, []string{"cell"}, names(opts.groupings[0]))
+			},
+		},
+		{desc: "repeated grouping", args: []string{"--group-by", "worker", "--group-by", "worker"},
+			wantErr: "group-by 'worker' is given more than once"},
+		{
+			desc: "statistics options",
+			args: []string{"--ci", "95", "--trim", "1%", "--trim-mode", "winsorize", "--sort", "wins_avg:desc",
+				"--warn-threshold", "50ms", "--crit-threshold", "200ms"},
+			check: func(t *testing.T, opts runOptions) {
+				assert.Equal(t, statsOptions{
+					ci: 95, trim: 0.01, winsorize: true, sort: "wins_avg:desc",
+					thresholds: thresholds{warning: 50 * time.Millisecond, critical: 200 * time.Millisecond},
+				}, opts.stats)
+			},
+		},
+		{desc: "confidence level", args: []string{"--ci", "100"}, wantErr: "confidence level must be between"},
+		{desc: "trim mode", args: []string{"--trim-mode", "cut"}, wantErr: "unknown trim mode 'cut'"},
+		{desc: "sort by a disabled column", args: []string{"--sort", "trim_max"}, wantErr: "invalid sort"},
+		{desc: "thresholds", args: []string{"--warn-threshold", "1s", "--crit-threshold", "500ms"},
+			wantErr: "crit-threshold 500ms must not be below warn-threshold 1s"},
+		{desc: "summary format", args: []string{"--summary-format", "xml"}, wantErr: "xml"},
+		{desc: "outliers", args: []string{"--outliers", "zscore"}, wantErr: "unknown outlier detection"},
+		{desc: "negative interval", args: []string{"--interval", "-1s"}, wantErr: "interval must not be negative"},
+		{desc: "interval-out without interval", args: []string{"--interval-out", "series.csv"},
+			wantErr: "--interval-out requires an --interval"},
+		{desc: "queue size", args: []string{"--queue-size", "0"}, wantErr: "queue-size must be at least 1"},
+	}
+	for _, tst := range cases {
+		flags := (&cmdRun{}).flagSet()
+		require.NoError(t, flags.Parse(tst.args), tst.desc)
+		opts, err := getRunOptions(flags)
+		if tst.wantErr != "" {
+			assert.ErrorContains(t, err, tst.wantErr, tst.desc)
+			continue
+		}
+		require.NoError(t, err, tst.desc)
+		tst.check(t, opts)
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lfordyce/tiger/pkg/table"
+)
+
+const (
+	// sgrWarning, sgrCritical and sgrSlowest are the ANSI SGR parameters of highlighted cells.
+	sgrWarning  = "33"
+	sgrCritical = "1;31"
+	sgrSlowest  = "1"
+	// slowestRows is the number of rows marked as slowest in the statistics tables.
+	slowestRows = 3
+)
+
+// formatLatency formats a latency given in milliseconds, scaled to µs, ms or s so that three decimals remain
+// meaningful.
+func formatLatency(ms float64) string {
+	switch abs := math.Abs(ms); {
+	case abs == 0:
+		return "0.000ms"
+	case abs < 1:
+		return fmt.Sprintf("%.3fµs", ms*1e3)
+	case abs < 1e3:
+		return fmt.Sprintf("%.3fms", ms)
+	default:
+		return fmt.Sprintf("%.3fs", ms/1e3)
+	}
+}
+
+// millis converts a latency given in milliseconds to a duration, the raw value of latency cells.
+func millis(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// latencyColumn returns a right aligned column of latencies.
+func latencyColumn(header string) table.Column {
+	return table.NewColumn(header).WithType(table.TypeDuration)
+}
+
+// thresholds are the latencies above which cells are highlighted, 0 disables a threshold.
+type thresholds struct {
+	warning  time.Duration
+	critical time.Duration
+}
+
+// style highlights latency cells above the thresholds.
+func (th thresholds) style(cell table.Cell) string {
+	if cell.Column.Type != table.TypeDuration {
+		return ""
+	}
+	latency, ok := cell.Raw.(time.Duration)
+	if !ok {
+		d, err := time.ParseDuration(cell.Text)
+		if err != nil {
+			return ""
+		}
+		latency = d
+	}
+	switch {
+	case th.critical > 0 && latency > th.critical:
+		return sgrCritical
+	case th.warning > 0 && latency > th.warning:
+		return sgrWarning
+	default:
+		return ""
+	}
+}
+
+// markSlowest highlights the latency cells of a statistics table by the thresholds and marks its slowest rows by
+// median. The rows are identified by their leading key columns.
+func markSlowest(t *table.Table, th thresholds, keys, median int) {
+	type ranked struct {
+		key    string
+		median time.Duration
+	}
+	rows := make([]ranked, 0, len(t.Data))
+	for i, row := range t.Data {
+		if i >= len(t.Raw) || median >= len(t.Raw[i]) {
+			continue
+		}
+		if d, ok := t.Raw[i][median].(time.Duration); ok {
+			rows = append(rows, ranked{key: strings.Join(row[:keys], "\x00"), median: d})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].median > rows[j].median })
+
+	// marking every row of a small table would not single out anything
+	slowest := make(map[string]bool, slowestRows)
+	for i := 0; i < slowestRows && len(rows) > slowestRows; i++ {
+		slowest[rows[i].key] = true
+	}
+
+	t.Style = func(cell table.Cell) string {
+		if sgr := th.style(cell); sgr != "" {
+			return sgr
+		}
+		if slowest[strings.Join(cell.Row[:keys], "\x00")] {
+			return sgrSlowest
+		}
+		return ""
+	}
+}
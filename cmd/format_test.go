@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lfordyce/tiger/pkg/statistics"
+	"github.com/lfordyce/tiger/pkg/table"
+)
+
+func TestFormatLatency(t *testing.T) {
+	cases := [...]struct {
+		ms   float64
+		want string
+	}{
+		{0, "0.000ms"},
+		{0.0005, "0.500µs"},
+		{0.999, "999.000µs"},
+		{1, "1.000ms"},
+		{12.3456, "12.346ms"},
+		{999.999, "999.999ms"},
+		{1000, "1.000s"},
+		{61500, "61.500s"},
+		{-0.25, "-250.000µs"},
+		{-2, "-2.000ms"},
+	}
+	for _, tst := range cases {
+		assert.Equal(t, tst.want, formatLatency(tst.ms), "formatLatency(%v)", tst.ms)
+	}
+}
+
+func TestThresholdsStyle(t *testing.T) {
+	th := thresholds{warning: 50 * time.Millisecond, critical: 200 * time.Millisecond}
+	duration := latencyColumn(medianHeader)
+	cases := [...]struct {
+		desc string
+		th   thresholds
+		cell table.Cell
+		want string
+	}{
+		{"below warning", th, table.Cell{Column: duration, Raw: 50 * time.Millisecond}, ""},
+		{"above warning", th, table.Cell{Column: duration, Raw: 51 * time.Millisecond}, sgrWarning},
+		{"above critical", th, table.Cell{Column: duration, Raw: time.Second}, sgrCritical},
+		{"text without raw value", th, table.Cell{Column: duration, Text: "75ms"}, sgrWarning},
+		{"unparsable text", th, table.Cell{Column: duration, Text: "-"}, ""},
+		{"not a latency", th, table.Cell{Column: table.NewColumn("COUNT").WithType(table.TypeInt), Text: "1000"}, ""},
+		{"critical only", thresholds{critical: 200 * time.Millisecond},
+			table.Cell{Column: duration, Raw: 100 * time.Millisecond}, ""},
+		{"disabled", thresholds{}, table.Cell{Column: duration, Raw: time.Hour}, ""},
+	}
+	for _, tst := range cases {
+		assert.Equal(t, tst.want, tst.th.style(tst.cell), tst.desc)
+	}
+}
+
+// medianStats returns the statistics of a group per median, one sample each.
+func medianStats(medians ...float64) []dataStats {
+	stats := make([]dataStats, 0, len(medians))
+	for i, m := range medians {
+		stats = append(stats, dataStats{
+			keys: []string{fmt.Sprintf("host_%06d", i)}, totalRun: 1, totalTime: m, minTime: m, maxTime: m,
+			median: m, average: m, values: []float64{m},
+		})
+	}
+	return stats
+}
+
+// styled returns the style of the first cell of every row by its key.
+func styled(tb table.Table) map[string]string {
+	styles := make(map[string]string, len(tb.Data))
+	for i, row := range tb.Data {
+		styles[row[0]] = tb.Style(table.Cell{Column: tb.Columns[0], Text: row[0], Row: row, RawRow: tb.Raw[i]})
+	}
+	return styles
+}
+
+func TestMarkSlowest(t *testing.T) {
+	tb := stateTable([]dimension{hostnameDimension}, medianStats(5, 40, 10, 30, 20), statsOptions{})
+	assert.Equal(t, map[string]string{
+		"host_000000": "",
+		"host_000001": sgrSlowest,
+		"host_000002": "",
+		"host_000003": sgrSlowest,
+		"host_000004": sgrSlowest,
+	}, styled(tb))
+
+	// with no more rows than are marked nothing is singled out
+	tb = stateTable([]dimension{hostnameDimension}, medianStats(5, 40, 10), statsOptions{})
+	for key, style := range styled(tb) {
+		assert.Empty(t, style, key)
+	}
+
+	// thresholds take precedence over the slowest rows
+	th := thresholds{warning: 35 * time.Millisecond}
+	tb = stateTable([]dimension{hostnameDimension}, medianStats(5, 40, 10, 30, 20), statsOptions{thresholds: th})
+	for i, row := range tb.Data {
+		if row[0] != "host_000001" {
+			continue
+		}
+		for c, col := range tb.Columns {
+			if col.Type == table.TypeDuration {
+				cell := table.Cell{Column: col, Index: c, Text: row[c], Raw: tb.Raw[i][c], Row: row, RawRow: tb.Raw[i]}
+				assert.Equal(t, sgrWarning, tb.Style(cell), col.Header)
+			}
+		}
+	}
+}
+
+func TestMarkSlowestMedianColumn(t *testing.T) {
+	// the median index passed by stateTable must point at the MEDIAN column for any number of key columns and
+	// optional columns
+	for _, dims := range [][]dimension{
+		{hostnameDimension},
+		{hostnameDimension, cellDimension},
+		{hostnameDimension, cellDimension, dimensions[1]},
+	} {
+		for _, opts := range []statsOptions{{}, {ci: 95, trim: 0.01}} {
+			tb := stateTable(dims, groupStats([]statistics.Sample{{HostnameID: "host_000000", Elapsed: 1}}, dims), opts)
+			require.Greater(t, len(tb.Columns), len(dims)+4)
+			assert.Equal(t, medianHeader, tb.Columns[len(dims)+4].Header, "%d key columns", len(dims))
+		}
+	}
+}
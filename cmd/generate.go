@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/lfordyce/tiger/internal/domain"
+	"github.com/lfordyce/tiger/pkg/generate"
+	"github.com/lfordyce/tiger/pkg/postgres"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// cmdGenerate handles the `tiger generate` sub-command
+type cmdGenerate struct {
+	gs *globalState
+}
+
+func (c *cmdGenerate) generate(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	rows, err := flags.GetInt("rows")
+	if err != nil {
+		return err
+	}
+	out, err := flags.GetString("out")
+	if err != nil {
+		return err
+	}
+	windows, err := flags.GetDurationSlice("windows")
+	if err != nil {
+		return err
+	}
+	distName, err := flags.GetString("distribution")
+	if err != nil {
+		return err
+	}
+	dist, err := generate.ParseDistribution(distName)
+	if err != nil {
+		return err
+	}
+	zipfS, err := flags.GetFloat64("zipf-s")
+	if err != nil {
+		return err
+	}
+	seed, err := flags.GetInt64("seed")
+	if err != nil {
+		return err
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	pgconn, err := postgres.GetConfig(flags)
+	if err != nil {
+		return fmt.Errorf("failed to parse postgres config cli flags: %w", err)
+	}
+	fmtProcess, err := domain.GetCsvConfig(flags)
+	if err != nil {
+		return fmt.Errorf("failed to parse csv config cli flags: %w", err)
+	}
+
+	repo, closeFunc, err := pgconn.Connect(c.gs.ctx)
+	if err != nil {
+		c.gs.logger.WithError(err).Error("Unable to connect to database")
+		return err
+	}
+	defer closeFunc()
+
+	dataset, err := repo.Describe(c.gs.ctx)
+	if err != nil {
+		return err
+	}
+	hosts := make([]generate.Host, 0, len(dataset.Hosts))
+	for _, h := range dataset.Hosts {
+		hosts = append(hosts, generate.Host{Name: h.Host, Rows: h.Rows})
+	}
+	c.gs.logger.WithFields(logrus.Fields{
+		"hosts": len(hosts),
+		"start": dataset.Start,
+		"end":   dataset.End,
+	}).Info("inspected dataset")
+
+	g, err := generate.New(generate.Config{
+		Hosts:        hosts,
+		Start:        dataset.Start,
+		End:          dataset.End,
+		Windows:      windows,
+		Distribution: dist,
+		ZipfS:        zipfS,
+		Seed:         seed,
+	})
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = c.gs.stdOut
+	var f *os.File
+	if out != "-" {
+		f, err = os.Create(out)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		// closed explicitly below once everything is written, this only covers the early returns
+		defer f.Close() // nolint:errcheck
+		w = f
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{fmtProcess.Hostname, fmtProcess.StartTime, fmtProcess.EndTime}); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+	for i := 0; i < rows; i++ {
+		r := g.Next()
+		record := []string{r.Host, fmtProcess.Timestamp.Format(r.Start), fmtProcess.Timestamp.Format(r.End)}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write csv record: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write csv records: %w", err)
+	}
+	if f != nil {
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to close output file: %w", err)
+		}
+	}
+
+	c.gs.logger.WithFields(logrus.Fields{
+		"rows":         rows,
+		"distribution": dist,
+		"seed":         seed,
+	}).Info("generated query parameters")
+	return nil
+}
+
+func (c *cmdGenerate) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.Int("rows", 1000, "Number of requests to generate")
+	flags.StringP("out", "o", "-", "Output file, \"-\" writes to stdout")
+	flags.DurationSlice("windows", []time.Duration{time.Hour}, "Window lengths to pick from for each request")
+	flags.String("distribution", string(generate.Uniform), "How hosts are picked, possible values are uniform,zipf,weighted")
+	flags.Float64("zipf-s", 1.1, "Skew of the zipf distribution, must be greater than 1")
+	flags.Int64("seed", 0, "Seed of the random generator, 0 picks a random seed")
+	flags.AddFlagSet(postgresFlagSet())
+	flags.AddFlagSet(csvFlagSet())
+	return flags
+}
+
+func getCmdGenerate(gs *globalState) *cobra.Command {
+	c := &cmdGenerate{
+		gs: gs,
+	}
+
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a query parameter file",
+		Long: `Generate a query parameter file from the contents of the cpu_usage table.
+
+The output uses the header names and timestamp format given by the --csv-* flags, so it can be passed
+to "tiger run" with the same flags.`,
+		Args: cobra.NoArgs,
+		RunE: c.generate,
+	}
+	generateCmd.Flags().SortFlags = false
+	generateCmd.Flags().AddFlagSet(c.flagSet())
+	return generateCmd
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lfordyce/tiger/pkg/statistics"
+	"github.com/lfordyce/tiger/pkg/table"
+)
+
+// dimension is an attribute of a sample, or of the request it measured, that statistics can be grouped by.
+type dimension struct {
+	name   string
+	header string
+	typ    table.Type
+	value  func(statistics.Sample) string
+}
+
+var (
+	hostnameDimension = dimension{ // nolint:gochecknoglobals
+		name:   "hostname",
+		header: hostnameHeader,
+		value:  func(s statistics.Sample) string { return s.HostnameID },
+	}
+	cellDimension = dimension{ // nolint:gochecknoglobals
+		name:   "cell",
+		header: cellHeader,
+		value:  func(s statistics.Sample) string { return s.Cell },
+	}
+
+	// dimensions are all attributes that can be passed to --group-by.
+	dimensions = []dimension{ // nolint:gochecknoglobals
+		hostnameDimension,
+		{
+			name:   "worker",
+			header: "WORKER",
+			typ:    table.TypeInt,
+			value:  func(s statistics.Sample) string { return strconv.Itoa(s.WorkerID) },
+		},
+		{
+			name:   "window-length",
+			header: "WINDOW",
+			typ:    table.TypeDuration,
+			value:  func(s statistics.Sample) string { return s.EndTime.Sub(s.StartTime).String() },
+		},
+		{
+			name:   "start-hour",
+			header: "START_HOUR",
+			value:  func(s statistics.Sample) string { return s.StartTime.Format("15") },
+		},
+		{
+			name:   "source-file",
+			header: "SOURCE",
+			value:  func(s statistics.Sample) string { return s.Source },
+		},
+		{
+			name:   "target",
+			header: "TARGET",
+			value:  func(s statistics.Sample) string { return s.Target },
+		},
+		cellDimension,
+		{
+			name:   "backend",
+			header: "BACKEND_PID",
+			typ:    table.TypeInt,
+			value:  func(s statistics.Sample) string { return strconv.FormatUint(uint64(s.BackendPID), 10) },
+		},
+		{
+			name:   "stage",
+			header: "STAGE",
+			typ:    table.TypeInt,
+			value:  func(s statistics.Sample) string { return strconv.Itoa(s.Stage) },
+		},
+	}
+)
+
+// parseDimensions parses a comma separated list of dimension names, each dimension may appear once.
+func parseDimensions(spec string) ([]dimension, error) {
+	var dims []dimension
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		for _, d := range dims {
+			if d.name == name {
+				return nil, fmt.Errorf("group-by dimension '%s' is repeated in '%s'", name, spec)
+			}
+		}
+		found := false
+		for _, d := range dimensions {
+			if d.name == name {
+				dims = append(dims, d)
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, 0, len(dimensions))
+			for _, d := range dimensions {
+				names = append(names, d.name)
+			}
+			return nil, fmt.Errorf("unknown group-by dimension '%s', possible values are %s", name,
+				strings.Join(names, ","))
+		}
+	}
+	return dims, nil
+}
+
+// getGroupings reads the repeatable group-by flag, every value produces its own breakdown table.
+func getGroupings(specs []string) ([][]dimension, error) {
+	groupings := make([][]dimension, 0, len(specs))
+	for _, spec := range specs {
+		dims, err := parseDimensions(spec)
+		if err != nil {
+			return nil, err
+		}
+		if hasGrouping(groupings, dims) {
+			return nil, fmt.Errorf("group-by '%s' is given more than once", spec)
+		}
+		groupings = append(groupings, dims)
+	}
+	return groupings, nil
+}
+
+// hasGrouping reports whether the groupings contain the dimensions in the same order.
+func hasGrouping(groupings [][]dimension, dims []dimension) bool {
+	for _, g := range groupings {
+		if groupTitle(g) == groupTitle(dims) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSort checks that the sort spec only names columns of every statistics table.
+func validateSort(groupings [][]dimension, opts statsOptions) error {
+	if opts.sort == "" {
+		return nil
+	}
+	for _, dims := range groupings {
+		t := buildTable(dims)
+		t.Columns = append(t.Columns, optionalColumns(opts)...)
+		if _, err := table.ParseSort(opts.sort, t.Columns); err != nil {
+			return fmt.Errorf("invalid sort of the statistics by %s: %w", strings.ToLower(groupTitle(dims)), err)
+		}
+	}
+	return nil
+}
+
+// groupTitle names the breakdown table of the dimensions, e.g. "HOSTNAME, WORKER".
+func groupTitle(dims []dimension) string {
+	names := make([]string, 0, len(dims))
+	for _, d := range dims {
+		names = append(names, strings.ToUpper(d.name))
+	}
+	return strings.Join(names, ", ")
+}
+
+// groupStats computes the statistics of the samples grouped by the given dimensions.
+func groupStats(samples []statistics.Sample, dims []dimension) []dataStats {
+	keys := make(map[string][]string)
+	collection := make(map[string][]float64)
+	for _, s := range samples {
+		values := make([]string, 0, len(dims))
+		for _, d := range dims {
+			values = append(values, d.value(s))
+		}
+		k := strings.Join(values, "\x00")
+		keys[k] = values
+		collection[k] = append(collection[k], s.Elapsed)
+	}
+
+	var dStats []dataStats
+	for k, v := range collection {
+		dStats = append(dStats, dataStats{
+			keys:      keys[k],
+			totalRun:  len(v),
+			totalTime: statistics.Sum(v),
+			minTime:   statistics.Min(v),
+			maxTime:   statistics.Max(v),
+			median:    statistics.Median(v),
+			average:   statistics.Mean(v),
+			values:    v,
+		})
+	}
+	return dStats
+}
+
+// hostStats computes the statistics of the samples grouped by hostname.
+func hostStats(samples []statistics.Sample) []dataStats {
+	return groupStats(samples, []dimension{hostnameDimension})
+}
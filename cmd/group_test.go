@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lfordyce/tiger/pkg/statistics"
+)
+
+// names returns the names of the dimensions.
+func names(dims []dimension) []string {
+	n := make([]string, 0, len(dims))
+	for _, d := range dims {
+		n = append(n, d.name)
+	}
+	return n
+}
+
+func TestParseDimensions(t *testing.T) {
+	cases := [...]struct {
+		spec    string
+		want    []string
+		wantErr string
+	}{
+		{spec: "hostname", want: []string{"hostname"}},
+		{spec: "stage, hostname", want: []string{"stage", "hostname"}},
+		{spec: "worker,window-length,start-hour,source-file,target,cell,backend",
+			want: []string{"worker", "window-length", "start-hour", "source-file", "target", "cell", "backend"}},
+		{spec: "host", wantErr: "unknown group-by dimension 'host'"},
+		{spec: "hostname,", wantErr: "unknown group-by dimension ''"},
+		{spec: "hostname,worker,hostname", wantErr: "group-by dimension 'hostname' is repeated"},
+	}
+	for _, tst := range cases {
+		dims, err := parseDimensions(tst.spec)
+		if tst.wantErr != "" {
+			assert.ErrorContains(t, err, tst.wantErr, tst.spec)
+			continue
+		}
+		require.NoError(t, err, tst.spec)
+		assert.Equal(t, tst.want, names(dims), tst.spec)
+	}
+}
+
+func TestGetGroupings(t *testing.T) {
+	groupings, err := getGroupings([]string{"hostname,worker", "start-hour", "worker,hostname"})
+	require.NoError(t, err)
+	require.Len(t, groupings, 3)
+	assert.Equal(t, []string{"hostname", "worker"}, names(groupings[0]))
+	assert.Equal(t, []string{"start-hour"}, names(groupings[1]))
+	assert.Equal(t, []string{"worker", "hostname"}, names(groupings[2]))
+	assert.True(t, hasGrouping(groupings, []dimension{dimensions[3]}))
+	assert.False(t, hasGrouping(groupings, []dimension{cellDimension}))
+
+	_, err = getGroupings([]string{"hostname", "worker", " hostname"})
+	assert.ErrorContains(t, err, "group-by ' hostname' is given more than once")
+
+	_, err = getGroupings([]string{"hostname", "cells"})
+	assert.ErrorContains(t, err, "unknown group-by dimension 'cells'")
+
+	groupings, err = getGroupings(nil)
+	require.NoError(t, err)
+	assert.Empty(t, groupings)
+}
+
+func TestValidateSort(t *testing.T) {
+	byHost := [][]dimension{{hostnameDimension}}
+	byHostAndWorker := [][]dimension{{hostnameDimension}, {hostnameDimension, dimensions[1]}}
+	cases := [...]struct {
+		desc      string
+		groupings [][]dimension
+		opts      statsOptions
+		wantErr   string
+	}{
+		{desc: "no sort", groupings: byHost},
+		{desc: "statistics column", groupings: byHost, opts: statsOptions{sort: "max:desc,hostname"}},
+		{desc: "unknown column", groupings: byHost, opts: statsOptions{sort: "p99"}, wantErr: "by hostname"},
+		{desc: "column of one grouping only", groupings: byHostAndWorker, opts: statsOptions{sort: "worker"},
+			wantErr: "invalid sort of the statistics by hostname"},
+		{desc: "variance column", groupings: byHost, opts: statsOptions{ci: 95, sort: "stddev:desc"}},
+		{desc: "variance column disabled", groupings: byHost, opts: statsOptions{sort: "stddev:desc"},
+			wantErr: "by hostname"},
+		{desc: "trim column", groupings: byHost, opts: statsOptions{trim: 0.01, sort: "trim_max"}},
+		{desc: "winsorize column", groupings: byHost, opts: statsOptions{trim: 0.01, winsorize: true, sort: "wins_avg"}},
+	}
+	for _, tst := range cases {
+		err := validateSort(tst.groupings, tst.opts)
+		if tst.wantErr != "" {
+			assert.ErrorContains(t, err, tst.wantErr, tst.desc)
+			continue
+		}
+		assert.NoError(t, err, tst.desc)
+	}
+}
+
+func TestGroupStats(t *testing.T) {
+	start := time.Date(2017, 1, 1, 8, 0, 0, 0, time.UTC)
+	samples := []statistics.Sample{
+		{HostnameID: "host_000000", WorkerID: 0, StartTime: start, EndTime: start.Add(time.Hour), Elapsed: 10},
+		{HostnameID: "host_000000", WorkerID: 1, StartTime: start, EndTime: start.Add(time.Hour), Elapsed: 30},
+		{HostnameID: "host_000001", WorkerID: 0, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour),
+			Elapsed: 20},
+		{HostnameID: "host_000000", WorkerID: 0, StartTime: start, EndTime: start.Add(time.Minute), Elapsed: 50},
+	}
+	window := dimensions[2]
+	stats := groupStats(samples, []dimension{hostnameDimension, window})
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].keys[0]+stats[i].keys[1] < stats[j].keys[0]+stats[j].keys[1]
+	})
+
+	require.Len(t, stats, 3)
+	assert.Equal(t, dataStats{
+		keys: []string{"host_000000", "1h0m0s"}, totalRun: 2, totalTime: 40, minTime: 10, maxTime: 30,
+		median: 20, average: 20, values: []float64{10, 30},
+	}, stats[0])
+	assert.Equal(t, dataStats{
+		keys: []string{"host_000000", "1m0s"}, totalRun: 1, totalTime: 50, minTime: 50, maxTime: 50,
+		median: 50, average: 50, values: []float64{50},
+	}, stats[1])
+	assert.Equal(t, []string{"host_000001", "1h0m0s"}, stats[2].keys)
+
+	// every group becomes a row of the statistics table, keyed by its dimensions
+	tb := stateTable([]dimension{hostnameDimension, window}, stats, statsOptions{})
+	require.Len(t, tb.Data, 3)
+	assert.Equal(t, []string{"HOSTNAME", "WINDOW"}, []string{tb.Columns[0].Header, tb.Columns[1].Header})
+	assert.Equal(t, []string{"host_000000", "1h0m0s", "2"}, tb.Data[0][:3])
+	assert.Equal(t, "HOSTNAME, WINDOW-LENGTH", groupTitle([]dimension{hostnameDimension, window}))
+
+	assert.Len(t, hostStats(samples), 2)
+	assert.Empty(t, groupStats(nil, []dimension{hostnameDimension}))
+}
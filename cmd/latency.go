@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lfordyce/tiger/pkg/statistics"
+	"github.com/lfordyce/tiger/pkg/table"
+)
+
+// latencyTable compares the latency reported by the database with the response time measured by tiger, and with the
+// response time corrected for coordinated omission: a slow query holds back the requests behind it, which the
+// uncorrected distributions do not show. Requests paced by --rate are measured from the time they were due and also
+// list their delay behind the schedule, closed-loop requests are corrected by the expected interval of their worker.
+func latencyTable(samples []statistics.Sample) table.Table {
+	query := make([]float64, 0, len(samples))
+	response := make([]float64, 0, len(samples))
+	delay := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		query = append(query, s.Elapsed)
+		response = append(response, float64(s.Overhead)/1e6)
+		if !s.Intended.IsZero() {
+			delay = append(delay, float64(s.Delay())/1e6)
+		}
+	}
+
+	t := table.NewTable([]table.Column{
+		table.NewColumn("LATENCY").WithLeftAlign(),
+		table.NewColumn("COUNT").WithType(table.TypeInt),
+		latencyColumn(minHeader),
+		latencyColumn("P50"),
+		latencyColumn("P90"),
+		latencyColumn("P99"),
+		latencyColumn(maxHeader),
+		latencyColumn(averageHeader),
+	}, []table.Row{})
+	type latencies struct {
+		name string
+		data []float64
+	}
+	rows := []latencies{{"query", query}, {"response", response}}
+	if len(delay) > 0 {
+		rows = append(rows, latencies{"schedule delay", delay})
+	}
+	rows = append(rows, latencies{"corrected", statistics.CorrectLatencies(samples)})
+	for _, d := range rows {
+		dist := statistics.Distribute(d.data)
+		t.Data = append(t.Data, []string{
+			d.name,
+			fmt.Sprint(dist.Count),
+			formatLatency(dist.Min),
+			formatLatency(dist.P50),
+			formatLatency(dist.P90),
+			formatLatency(dist.P99),
+			formatLatency(dist.Max),
+			formatLatency(dist.Mean),
+		})
+	}
+	return t
+}
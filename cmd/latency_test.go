@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lfordyce/tiger/pkg/statistics"
+)
+
+func TestLatencyTable(t *testing.T) {
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	sample := func(intended time.Time, delay, response time.Duration) statistics.Sample {
+		return statistics.Sample{
+			HostnameID: "host_000000", Elapsed: float64(response) / 1e6, Overhead: response, Intended: intended,
+			Started: start.Add(delay), Completed: start.Add(delay + response),
+		}
+	}
+
+	// closed-loop requests are corrected by the requests the worker missed during the stall
+	closedLoop := []statistics.Sample{
+		sample(time.Time{}, 0, time.Millisecond),
+		sample(time.Time{}, 0, time.Millisecond),
+		sample(time.Time{}, 0, 5*time.Millisecond),
+	}
+	tb := latencyTable(closedLoop)
+	require.Len(t, tb.Data, 3)
+	assert.Equal(t, []string{"query", "3"}, tb.Data[0][:2])
+	assert.Equal(t, []string{"response", "3"}, tb.Data[1][:2])
+	assert.Equal(t, []string{"corrected", "7", "1.000ms"}, tb.Data[2][:3])
+
+	// paced requests are measured from the time they were due
+	paced := []statistics.Sample{
+		sample(start, 0, time.Millisecond),
+		sample(start, 40*time.Millisecond, 10*time.Millisecond),
+	}
+	tb = latencyTable(paced)
+	require.Len(t, tb.Data, 4)
+	assert.Equal(t, []string{"schedule delay", "2", "0.000ms"}, tb.Data[2][:3])
+	assert.Equal(t, "40.000ms", tb.Data[2][6])
+	assert.Equal(t, []string{"corrected", "2", "1.000ms"}, tb.Data[3][:3])
+	assert.Equal(t, "50.000ms", tb.Data[3][6])
+}
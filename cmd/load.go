@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lfordyce/tiger/internal/domain"
+	"github.com/lfordyce/tiger/pkg/csv"
+	"github.com/lfordyce/tiger/pkg/postgres"
+	"github.com/lfordyce/tiger/pkg/queue"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// cmdLoad handles the `tiger load` sub-command
+type cmdLoad struct {
+	gs *globalState
+}
+
+// loadProgress keeps track of the rows copied by concurrent batches.
+type loadProgress struct {
+	start   time.Time
+	rows    int64
+	skipped int64
+
+	mu  sync.Mutex
+	err error
+}
+
+func (p *loadProgress) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// failed returns the first copy error, it takes precedence over the errors caused by cancelling the load.
+func (p *loadProgress) failed() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *loadProgress) fields() logrus.Fields {
+	rows := atomic.LoadInt64(&p.rows)
+	elapsed := time.Since(p.start)
+	return logrus.Fields{
+		"rows":     rows,
+		"skipped":  atomic.LoadInt64(&p.skipped),
+		"elapsed":  elapsed,
+		"rows_sec": fmt.Sprintf("%.0f", float64(rows)/elapsed.Seconds()),
+	}
+}
+
+func (c *cmdLoad) load(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	config, err := getConfig(flags)
+	if err != nil {
+		return err
+	}
+	batchSize, err := flags.GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	if batchSize < 1 {
+		return fmt.Errorf("batch-size must be at least 1, got %d", batchSize)
+	}
+	tsHeader, err := flags.GetString("ts-hdr")
+	if err != nil {
+		return err
+	}
+	hostHeader, err := flags.GetString("host-hdr")
+	if err != nil {
+		return err
+	}
+	usageHeader, err := flags.GetString("usage-hdr")
+	if err != nil {
+		return err
+	}
+	timestamp, err := domain.GetTimestampConfig(flags)
+	if err != nil {
+		return err
+	}
+
+	// the header is checked before connecting, so that a mistyped column name fails fast
+	file := stdinOrFile(args[0], c.gs.stdIn)
+	reader := csv.WithIoReader(file)
+	defer reader.Close()
+	if err := checkHeader(reader.Header(), tsHeader, hostHeader, usageHeader); err != nil {
+		if readErr := reader.Error(); readErr != nil {
+			return fmt.Errorf("failed to read input file: %w", readErr)
+		}
+		return err
+	}
+
+	pgconn, err := postgres.GetConfig(flags)
+	if err != nil {
+		return fmt.Errorf("failed to parse postgres config cli flags: %w", err)
+	}
+	repo, closeFunc, err := pgconn.Connect(c.gs.ctx)
+	if err != nil {
+		c.gs.logger.WithError(err).Error("Unable to connect to database")
+		return err
+	}
+	defer closeFunc()
+
+	if err := repo.EnsureSchema(c.gs.ctx); err != nil {
+		return err
+	}
+
+	// the first failed batch cancels the load, the batches in flight are aborted and the reader stops
+	ctx, cancel := context.WithCancel(c.gs.ctx)
+	defer cancel()
+
+	// at most a batch per worker is queued besides the batches being copied, enough to keep the workers busy, the
+	// reader waits for a free slot instead of buffering the file
+	qd := queue.NewDispatcher(config.Workers, queue.Options{Capacity: config.Workers})
+	go qd.Run(ctx)
+	defer qd.Stop()
+
+	progress := &loadProgress{start: time.Now()}
+	submit := func(batch []postgres.UsageRow) error {
+		return qd.Queue(queue.NewJob(func(int) error {
+			n, err := repo.CopyUsage(ctx, batch)
+			atomic.AddInt64(&progress.rows, n)
+			if err != nil {
+				progress.fail(err)
+				cancel()
+				return err
+			}
+			return nil
+		}, nil, nil))
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.gs.logger.WithFields(progress.fields()).Info("loading")
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	batch := make([]postgres.UsageRow, 0, batchSize)
+	for data := range reader.C() {
+		if ctx.Err() != nil {
+			break
+		}
+		entry := c.gs.logger.WithFields(logrus.Fields{"line": data.Line(), "offset": data.Offset()})
+		ts, err := timestamp.Parse(data.Get(tsHeader))
+		if err != nil {
+			entry.WithError(fmt.Errorf("failed to parse timestamp: %w", err)).Error()
+			atomic.AddInt64(&progress.skipped, 1)
+			continue
+		}
+		usage, err := strconv.ParseFloat(data.Get(usageHeader), 64)
+		if err != nil {
+			entry.WithError(fmt.Errorf("failed to parse usage: %w", err)).Error()
+			atomic.AddInt64(&progress.skipped, 1)
+			continue
+		}
+		host := data.Get(hostHeader)
+		if host == "" {
+			entry.WithError(errors.New("empty host")).Error()
+			atomic.AddInt64(&progress.skipped, 1)
+			continue
+		}
+		batch = append(batch, postgres.UsageRow{Time: ts, Host: host, Usage: usage})
+		if len(batch) == batchSize {
+			if err := submit(batch); err != nil {
+				if failed := progress.failed(); failed != nil {
+					return failed
+				}
+				return fmt.Errorf("failed to queue batch: %w", err)
+			}
+			batch = make([]postgres.UsageRow, 0, batchSize)
+		}
+	}
+	if err := progress.failed(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("load cancelled: %w", err)
+	}
+	if len(batch) > 0 {
+		if err := submit(batch); err != nil {
+			return fmt.Errorf("failed to queue batch: %w", err)
+		}
+	}
+	if err := qd.Drain(ctx); err != nil {
+		if failed := progress.failed(); failed != nil {
+			return failed
+		}
+		return fmt.Errorf("failed to finish copying batches: %w", err)
+	}
+
+	if err := reader.Error(); err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	if err := progress.failed(); err != nil {
+		return err
+	}
+	c.gs.logger.WithFields(progress.fields()).Info("loaded dataset")
+	return nil
+}
+
+// checkHeader verifies that the header of the input file has the columns, a mistyped column name would otherwise
+// load every row with an empty value.
+func checkHeader(header []string, columns ...string) error {
+	present := make(map[string]bool, len(header))
+	for _, h := range header {
+		present[h] = true
+	}
+	for _, c := range columns {
+		if !present[c] {
+			return fmt.Errorf("column '%s' is missing from the csv header %q", c, header)
+		}
+	}
+	return nil
+}
+
+func (c *cmdLoad) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.IntP("workers", "w", 4, "Number of batches copied concurrently.")
+	flags.Int("batch-size", 10000, "Number of rows copied per batch")
+	flags.String("ts-hdr", "ts", "The name of the CSV timestamp field")
+	flags.String("host-hdr", "host", "The name of the CSV host field")
+	flags.String("usage-hdr", "usage", "The name of the CSV usage field")
+	flags.AddFlagSet(timestampFlagSet())
+	flags.AddFlagSet(postgresFlagSet())
+	return flags
+}
+
+func getCmdLoad(gs *globalState) *cobra.Command {
+	c := &cmdLoad{
+		gs: gs,
+	}
+
+	loadCmd := &cobra.Command{
+		Use:   "load",
+		Short: "Load the cpu_usage dataset",
+		Long: `Load the cpu_usage dataset from the client side.
+
+The cpu_usage table and hypertable are created if they are missing, then the file is streamed into
+the database with COPY in concurrent batches.`,
+		Args: exactArgsWithMsg(1, "arg should either be \"-\", if reading data from stdin, or a path to a data file"),
+		RunE: c.load,
+	}
+	loadCmd.Flags().SortFlags = false
+	loadCmd.Flags().AddFlagSet(c.flagSet())
+	return loadCmd
+}
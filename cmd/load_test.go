@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHeader(t *testing.T) {
+	cases := [...]struct {
+		desc   string
+		header []string
+		err    bool
+	}{
+		{desc: "all columns", header: []string{"ts", "host", "usage"}},
+		{desc: "extra columns", header: []string{"id", "usage", "host", "ts"}},
+		{desc: "mistyped host column", header: []string{"ts", "hostname", "usage"}, err: true},
+		{desc: "empty header", header: []string{}, err: true},
+	}
+	for _, tst := range cases {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			err := checkHeader(tst.header, "ts", "host", "usage")
+			if tst.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
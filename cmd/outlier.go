@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lfordyce/tiger/pkg/statistics"
+	"github.com/lfordyce/tiger/pkg/table"
+)
+
+const (
+	outliersNone = "none"
+	// outliersIQR flags samples outside the Tukey fences of their hostname.
+	outliersIQR = "iqr"
+	// outliersMAD flags samples more than 3 scaled median absolute deviations from the median of their hostname.
+	outliersMAD = "mad"
+
+	trimModeTrim      = "trim"
+	trimModeWinsorize = "winsorize"
+
+	iqrFactor = 1.5
+	madFactor = 3
+)
+
+// statsOptions are the optional figures added to the statistics tables.
+type statsOptions struct {
+	// ci is the confidence level in percent, 0 disables the variance columns.
+	ci float64
+	// trim is the fraction of samples cut from each tail for the trimmed figures, 0 disables them.
+	trim float64
+	// winsorize clamps the tails instead of cutting them.
+	winsorize bool
+	// sort is the sort spec of the statistics tables, see table.ParseSort.
+	sort string
+	// thresholds highlight slow latencies on colour terminals.
+	thresholds thresholds
+}
+
+// parseTrim parses the fraction cut from each tail, given in percent with an optional % sign, e.g. "1%".
+func parseTrim(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid trim '%s', expected a percentage such as 1%%: %w", s, err)
+	}
+	if percent < 0 || percent >= 50 {
+		return 0, fmt.Errorf("trim must be between 0%% and 50%%, got %s", s)
+	}
+	return percent / 100, nil
+}
+
+func parseTrimMode(s string) (bool, error) {
+	switch s {
+	case trimModeTrim:
+		return false, nil
+	case trimModeWinsorize:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown trim mode '%s', possible values are %s,%s", s, trimModeTrim, trimModeWinsorize)
+	}
+}
+
+// trimColumns are the optional columns with the figures of the trimmed samples, added with --trim.
+func trimColumns(opts statsOptions) []table.Column {
+	if opts.trim <= 0 {
+		return nil
+	}
+	prefix := "TRIM_"
+	if opts.winsorize {
+		prefix = "WINS_"
+	}
+	return []table.Column{
+		latencyColumn(prefix + maxHeader),
+		latencyColumn(prefix + averageHeader),
+	}
+}
+
+// trimRow computes the values of the trimColumns.
+func trimRow(values []float64, opts statsOptions) []string {
+	if opts.trim <= 0 {
+		return nil
+	}
+	var trimmed []float64
+	if opts.winsorize {
+		trimmed = statistics.Winsorize(values, opts.trim)
+	} else {
+		trimmed = statistics.Trim(values, opts.trim)
+	}
+	return []string{
+		formatLatency(statistics.Max(trimmed)),
+		formatLatency(statistics.Mean(trimmed)),
+	}
+}
+
+// optionalColumns are all columns enabled by the options.
+func optionalColumns(opts statsOptions) []table.Column {
+	return append(varianceColumns(opts.ci), trimColumns(opts)...)
+}
+
+// optionalRow computes the values of the optionalColumns.
+func optionalRow(values []float64, opts statsOptions) []string {
+	return append(varianceRow(values, opts.ci), trimRow(values, opts)...)
+}
+
+func validateOutliers(method string) error {
+	switch method {
+	case outliersNone, outliersIQR, outliersMAD:
+		return nil
+	default:
+		return fmt.Errorf("unknown outlier detection '%s', possible values are %s,%s,%s",
+			method, outliersNone, outliersIQR, outliersMAD)
+	}
+}
+
+// outlier is a sample that lies outside the bounds of the other samples of its hostname.
+type outlier struct {
+	sample statistics.Sample
+	bounds statistics.Bounds
+}
+
+// findOutliers detects the outliers of every hostname, slowest first.
+func findOutliers(samples []statistics.Sample, method string) []outlier {
+	byHost := make(map[string][]statistics.Sample)
+	for _, s := range samples {
+		byHost[s.HostnameID] = append(byHost[s.HostnameID], s)
+	}
+
+	var outliers []outlier
+	for _, host := range byHost {
+		values := make([]float64, 0, len(host))
+		for _, s := range host {
+			values = append(values, s.Elapsed)
+		}
+		var bounds statistics.Bounds
+		if method == outliersMAD {
+			bounds = statistics.MADBounds(values, madFactor)
+		} else {
+			bounds = statistics.IQRBounds(values, iqrFactor)
+		}
+		for _, s := range host {
+			if !bounds.Contains(s.Elapsed) {
+				outliers = append(outliers, outlier{sample: s, bounds: bounds})
+			}
+		}
+	}
+	sort.Slice(outliers, func(i, j int) bool {
+		return outliers[i].sample.Elapsed > outliers[j].sample.Elapsed
+	})
+	return outliers
+}
+
+// outliersTable lists the outliers with the parameters of their request.
+func outliersTable(outliers []outlier) table.Table {
+	t := table.NewTable([]table.Column{
+		table.NewColumn(hostnameHeader).WithLeftAlign(),
+		table.NewColumn("START_TIME").WithLeftAlign(),
+		table.NewColumn("END_TIME").WithLeftAlign(),
+		table.NewColumn(cellHeader).WithLeftAlign(),
+		table.NewColumn("LINE").WithType(table.TypeInt),
+		table.NewColumn("WORKER").WithType(table.TypeInt),
+		latencyColumn("ELAPSED"),
+		table.NewColumn("BOUNDS"),
+	}, []table.Row{})
+	for _, o := range outliers {
+		t.Data = append(t.Data, []string{
+			o.sample.HostnameID,
+			o.sample.StartTime.Format("2006-01-02 15:04:05"),
+			o.sample.EndTime.Format("2006-01-02 15:04:05"),
+			o.sample.Cell,
+			fmt.Sprint(o.sample.Line),
+			fmt.Sprint(o.sample.WorkerID),
+			formatLatency(o.sample.Elapsed),
+			formatLatency(o.bounds.Lower) + ".." + formatLatency(o.bounds.Upper),
+		})
+	}
+	return t
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lfordyce/tiger/pkg/statistics"
+)
+
+func TestParseTrim(t *testing.T) {
+	cases := [...]struct {
+		spec    string
+		want    float64
+		wantErr string
+	}{
+		{spec: "", want: 0},
+		{spec: "0", want: 0},
+		{spec: "1%", want: 0.01},
+		{spec: " 5 ", want: 0.05},
+		{spec: "49.5%", want: 0.495},
+		{spec: "50%", wantErr: "trim must be between 0% and 50%, got 50%"},
+		{spec: "-1", wantErr: "trim must be between 0% and 50%"},
+		{spec: "1%%", wantErr: "invalid trim '1%%'"},
+		{spec: "one", wantErr: "invalid trim 'one'"},
+	}
+	for _, tst := range cases {
+		trim, err := parseTrim(tst.spec)
+		if tst.wantErr != "" {
+			assert.ErrorContains(t, err, tst.wantErr, tst.spec)
+			continue
+		}
+		require.NoError(t, err, tst.spec)
+		assert.InDelta(t, tst.want, trim, 1e-9, tst.spec)
+	}
+}
+
+func TestParseTrimMode(t *testing.T) {
+	winsorize, err := parseTrimMode(trimModeTrim)
+	require.NoError(t, err)
+	assert.False(t, winsorize)
+
+	winsorize, err = parseTrimMode(trimModeWinsorize)
+	require.NoError(t, err)
+	assert.True(t, winsorize)
+
+	_, err = parseTrimMode("clamp")
+	assert.ErrorContains(t, err, "unknown trim mode 'clamp'")
+}
+
+func TestValidateOutliers(t *testing.T) {
+	for _, method := range []string{outliersNone, outliersIQR, outliersMAD} {
+		assert.NoError(t, validateOutliers(method), method)
+	}
+	assert.ErrorContains(t, validateOutliers("zscore"), "unknown outlier detection 'zscore'")
+}
+
+func TestTrimColumns(t *testing.T) {
+	// a slow tail so that cutting and clamping it give different averages
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 10, 1000}
+	cases := [...]struct {
+		desc    string
+		opts    statsOptions
+		headers []string
+		row     []string
+	}{
+		{desc: "disabled", opts: statsOptions{}},
+		{desc: "disabled in winsorize mode", opts: statsOptions{winsorize: true}},
+		{desc: "trim", opts: statsOptions{trim: 0.1},
+			headers: []string{"TRIM_MAX", "TRIM_AVG"}, row: []string{"10.000ms", "5.625ms"}},
+		{desc: "winsorize", opts: statsOptions{trim: 0.1, winsorize: true},
+			headers: []string{"WINS_MAX", "WINS_AVG"}, row: []string{"10.000ms", "5.700ms"}},
+	}
+	for _, tst := range cases {
+		columns := optionalColumns(tst.opts)
+		headers := make([]string, 0, len(columns))
+		for _, c := range columns {
+			headers = append(headers, c.Header)
+		}
+		if len(tst.headers) == 0 {
+			assert.Empty(t, headers, tst.desc)
+			assert.Empty(t, optionalRow(values, tst.opts), tst.desc)
+		} else {
+			assert.Equal(t, tst.headers, headers, tst.desc)
+			assert.Equal(t, tst.row, optionalRow(values, tst.opts), tst.desc)
+		}
+
+		// the statistics tables end with the optional columns
+		samples := make([]statistics.Sample, 0, len(values))
+		for _, v := range values {
+			samples = append(samples, statistics.Sample{HostnameID: "host_000000", Elapsed: v})
+		}
+		tb := stateTable([]dimension{hostnameDimension}, hostStats(samples), tst.opts)
+		require.Len(t, tb.Data, 1)
+		require.Len(t, tb.Columns, len(statColumns())+len(tst.headers)+1, tst.desc)
+		for i, v := range tst.row {
+			assert.Equal(t, v, tb.Data[0][len(tb.Columns)-len(tst.row)+i], tst.desc)
+		}
+	}
+
+	// trimming combines with the variance columns, which come first
+	columns := optionalColumns(statsOptions{ci: 95, trim: 0.1})
+	require.Len(t, columns, 6)
+	assert.Equal(t, stdDevHeader, columns[0].Header)
+	assert.Equal(t, "TRIM_AVG", columns[5].Header)
+}
+
+func TestFindOutliers(t *testing.T) {
+	var samples []statistics.Sample
+	for i, v := range []float64{10, 11, 12, 12, 13, 14, 100} {
+		samples = append(samples, statistics.Sample{HostnameID: "host_000000", Line: i, Elapsed: v})
+	}
+	// the slow sample of one host is ordinary for another
+	for i, v := range []float64{90, 95, 100, 105, 110} {
+		samples = append(samples, statistics.Sample{HostnameID: "host_000001", Line: 10 + i, Elapsed: v})
+	}
+
+	for _, method := range []string{outliersIQR, outliersMAD} {
+		outliers := findOutliers(samples, method)
+		require.Len(t, outliers, 1, method)
+		assert.Equal(t, 6, outliers[0].sample.Line, method)
+		assert.False(t, outliers[0].bounds.Contains(100), method)
+
+		tb := outliersTable(outliers)
+		require.Len(t, tb.Data, 1, method)
+		assert.Equal(t, "100.000ms", tb.Data[0][6], method)
+	}
+}
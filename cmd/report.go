@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/lfordyce/tiger/pkg/postgres"
+	"github.com/lfordyce/tiger/pkg/report"
+	"github.com/lfordyce/tiger/pkg/statistics"
+)
+
+const (
+	// histogramBins is the number of bars of the latency histograms.
+	histogramBins = 40
+	// seriesPoints is the number of intervals the latency-over-time charts are split into without --interval.
+	seriesPoints = 60
+)
+
+// reportFields lists the run metadata in the html report.
+func (m runMetadata) reportFields() []report.Field {
+	return []report.Field{
+		{Name: "version", Value: m.Version},
+		{Name: "target", Value: m.Target},
+		{Name: "workers", Value: strconv.Itoa(m.Workers)},
+		{Name: "conn mode", Value: string(m.ConnMode)},
+		{Name: "exec mode", Value: string(m.ExecMode)},
+		{Name: "prepared", Value: strconv.FormatBool(m.Prepared)},
+		{Name: "max conns", Value: fmt.Sprint(m.MaxConns)},
+		{Name: "min conns", Value: fmt.Sprint(m.MinConns)},
+		{Name: "started", Value: m.Started.Format(time.RFC3339)},
+	}
+}
+
+// buildReport collects the charts of every target and the tables printed by run.
+func buildReport(
+	meta runMetadata, flags *pflag.FlagSet, targets []postgres.Target, byTarget map[string][]statistics.Sample,
+	sections []resultSection, interval time.Duration,
+) report.Report {
+	r := report.Report{
+		Title:     "TIGER BENCHMARK REPORT",
+		Generated: time.Now(),
+		Metadata:  meta.reportFields(),
+		Flags:     report.Flags(flags),
+	}
+
+	for _, t := range targets {
+		samples := byTarget[t.Name]
+		section := report.Section{Title: "Target " + t.Name, Charts: targetCharts(samples, interval)}
+		for _, s := range sections {
+			if s.target == t.Name {
+				section.Tables = append(section.Tables, reportTable(s))
+			}
+		}
+		r.Sections = append(r.Sections, section)
+	}
+
+	comparison := report.Section{Title: "Comparison"}
+	for _, s := range sections {
+		if s.target == "" {
+			comparison.Tables = append(comparison.Tables, reportTable(s))
+		}
+	}
+	if len(comparison.Tables) > 0 {
+		r.Sections = append(r.Sections, comparison)
+	}
+	return r
+}
+
+func reportTable(s resultSection) report.Table {
+	return report.Table{Title: s.title, Headers: s.table.Headers(), Rows: s.table.Rows()}
+}
+
+// targetCharts draws the latency histogram, the box plots by hostname and the latency over time of a target.
+func targetCharts(samples []statistics.Sample, interval time.Duration) []report.Chart {
+	values := make([]float64, 0, len(samples))
+	byHost := make(map[string][]float64)
+	for _, s := range samples {
+		values = append(values, s.Elapsed)
+		byHost[s.HostnameID] = append(byHost[s.HostnameID], s.Elapsed)
+	}
+
+	hosts := make([]string, 0, len(byHost))
+	for host := range byHost {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	groups := make([]report.Group, 0, len(hosts))
+	for _, host := range hosts {
+		groups = append(groups, report.Group{Label: host, Values: byHost[host]})
+	}
+
+	if interval <= 0 {
+		interval = seriesInterval(samples)
+	}
+	var p50, p95, peak []report.Point
+	series := statistics.Series(samples, interval)
+	for _, i := range series {
+		if i.Count == 0 {
+			continue
+		}
+		x := i.Start.Sub(series[0].Start).Seconds()
+		p50 = append(p50, report.Point{X: x, Y: i.P50})
+		p95 = append(p95, report.Point{X: x, Y: i.P95})
+		peak = append(peak, report.Point{X: x, Y: i.Max})
+	}
+	lines := []report.Line{
+		{Name: "p50", Color: "#4e79a7", Points: p50},
+		{Name: "p95", Color: "#f28e2b", Points: p95},
+		{Name: "max", Color: "#e15759", Points: peak},
+	}
+
+	return []report.Chart{
+		{Title: "Latency histogram", SVG: report.Histogram(values, histogramBins)},
+		{Title: "Latency by hostname", SVG: report.BoxPlot(groups)},
+		{
+			Title: fmt.Sprintf("Latency over time (%s intervals)", interval),
+			SVG: report.LineChart(lines, "time since start", func(x float64) string {
+				return "+" + time.Duration(x*float64(time.Second)).Round(time.Millisecond).String()
+			}),
+		},
+	}
+}
+
+// seriesInterval splits the time span of the samples into seriesPoints intervals.
+func seriesInterval(samples []statistics.Sample) time.Duration {
+	if len(samples) == 0 {
+		return time.Second
+	}
+	first, last := samples[0].Completed, samples[0].Completed
+	for _, s := range samples {
+		if s.Completed.Before(first) {
+			first = s.Completed
+		}
+		if s.Completed.After(last) {
+			last = s.Completed
+		}
+	}
+	interval := (last.Sub(first) / seriesPoints).Round(time.Millisecond)
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	return interval
+}
+
+// writeReport writes the html report to the given file.
+func writeReport(path string, r report.Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cmd.writeReport: failed to create report file %w", err)
+	}
+	defer f.Close()
+
+	if err := r.Write(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
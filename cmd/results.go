@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lfordyce/tiger/pkg/table"
+)
+
+const (
+	overflowEllipsis = "ellipsis"
+	overflowWrap     = "wrap"
+)
+
+// resultSection is a titled table of the benchmark results.
+type resultSection struct {
+	// target is the name of the target the table describes, empty if it compares several targets.
+	target string
+	title  string
+	table  table.Table
+	// summary marks the tables shown outside the collapsed full breakdown of the markdown summary.
+	summary bool
+}
+
+// outputOptions control how the result tables are printed.
+type outputOptions struct {
+	format   string
+	maxWidth int
+	overflow table.Overflow
+	hidden   []string
+	// color highlights the cells styled by the tables, only set for colour terminals.
+	color bool
+}
+
+func validateSummaryFormat(format string) error {
+	for _, f := range table.Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown summary format '%s', possible values are %s", format, strings.Join(table.Formats, ","))
+}
+
+func parseOverflow(s string) (table.Overflow, error) {
+	switch s {
+	case overflowEllipsis:
+		return table.OverflowEllipsis, nil
+	case overflowWrap:
+		return table.OverflowWrap, nil
+	default:
+		return 0, fmt.Errorf("unknown overflow '%s', possible values are %s,%s", s, overflowEllipsis, overflowWrap)
+	}
+}
+
+// renderResults writes the result tables in the requested format. Text and box tables are titled, csv and tsv
+// tables are preceded by a comment line with their title, json is a single array with an object per table.
+func renderResults(meta runMetadata, sections []resultSection, opts outputOptions, w io.Writer) error {
+	switch opts.format {
+	case table.FormatMarkdown:
+		return renderMarkdown(meta, sections, w)
+	case table.FormatJSON:
+		return renderJSON(sections, w)
+	}
+
+	r, err := table.NewRenderer(opts.format, table.Options{
+		MaxWidth: opts.maxWidth, Overflow: opts.overflow, Color: opts.color,
+	})
+	if err != nil {
+		return err
+	}
+	for i, s := range sections {
+		switch opts.format {
+		case table.FormatCSV, table.FormatTSV:
+			if i > 0 {
+				fmt.Fprint(w, "\n")
+			}
+			fmt.Fprintf(w, "# %s\n", s.title)
+		default:
+			fmt.Fprintf(w, "%s:\n", s.title)
+		}
+		if err := r.Render(w, &s.table); err != nil {
+			return err
+		}
+		if opts.format == table.FormatText || opts.format == table.FormatBox {
+			fmt.Fprint(w, "\n\n")
+		}
+	}
+	return nil
+}
+
+// renderJSON writes all tables as one json array, so scripts can pick the tables they need by title or target.
+func renderJSON(sections []resultSection, w io.Writer) error {
+	type jsonSection struct {
+		Title  string          `json:"title"`
+		Target string          `json:"target,omitempty"`
+		Rows   json.RawMessage `json:"rows"`
+	}
+	out := make([]jsonSection, 0, len(sections))
+	for _, s := range sections {
+		rows, err := s.table.JSON()
+		if err != nil {
+			return err
+		}
+		out = append(out, jsonSection{Title: s.title, Target: s.target, Rows: rows})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("cmd.renderJSON: failed to encode results %w", err)
+	}
+	return nil
+}
+
+// renderMarkdown writes the tables as GitHub-flavoured markdown, to be pasted into pull request comments. The summary
+// tables are shown right away, the full breakdown is collapsed.
+func renderMarkdown(meta runMetadata, sections []resultSection, w io.Writer) error {
+	fmt.Fprintf(w, "**tiger %s** · target `%s`", meta.Version, meta.Target)
+	if meta.Workers > 0 {
+		fmt.Fprintf(w, " · %d workers", meta.Workers)
+	}
+	fmt.Fprint(w, "\n\n")
+	for _, s := range sections {
+		if s.summary {
+			if err := renderMarkdownSection(s, w); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprint(w, "<details>\n<summary>Full breakdown</summary>\n\n")
+	for _, s := range sections {
+		if !s.summary {
+			if err := renderMarkdownSection(s, w); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprint(w, "</details>\n")
+	return nil
+}
+
+func renderMarkdownSection(s resultSection, w io.Writer) error {
+	fmt.Fprintf(w, "#### %s\n\n", s.title)
+	if err := (table.MarkdownRenderer{}).Render(w, &s.table); err != nil {
+		return err
+	}
+	fmt.Fprint(w, "\n")
+	return nil
+}
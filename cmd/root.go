@@ -125,7 +125,7 @@ func newRootCommand(gs *globalState) *rootCommand {
 	rootCmd.SetIn(gs.stdIn)
 
 	subCommands := []func(*globalState) *cobra.Command{
-		getCmdRun, getCmdVersion,
+		getCmdRun, getCmdSweep, getCmdValidate, getCmdGenerate, getCmdLoad, getCmdVersion,
 	}
 
 	for _, sc := range subCommands {
@@ -252,16 +252,20 @@ func (c *rootCommand) setupLoggers() (<-chan struct{}, error) {
 }
 
 func LogDurationHandler(next domain.Handler, id int, logger *logrus.Logger, write StreamWrite) domain.Handler {
-	return domain.HandlerFunc(func(r domain.Request) (result float64, err error) {
+	return domain.HandlerFunc(func(r domain.Request) (result domain.Result, err error) {
 		defer func(start time.Time) {
 			dur := time.Since(start)
 			e := logger.WithFields(logrus.Fields{
 				"worker_id":    id,
 				"dur_ms":       dur,
-				"query_dur_ms": result,
+				"query_dur_ms": result.Elapsed,
+				"backend_pid":  result.BackendPID,
 				"host_id":      r.HostID,
 				"start_time":   r.StartTime,
 				"end_time":     r.EndTime,
+				"line":         r.Line,
+				"target":       r.Target,
+				"cell":         r.Cell(),
 			})
 			if err != nil {
 				e.WithError(err).Error()
@@ -269,11 +273,20 @@ func LogDurationHandler(next domain.Handler, id int, logger *logrus.Logger, writ
 				e.Debug("processing statistics")
 				write <- statistics.Sample{
 					WorkerID:   id,
-					Elapsed:    result,
+					Elapsed:    result.Elapsed,
+					BackendPID: result.BackendPID,
+					Target:     r.Target,
+					Cell:       r.Cell(),
+					Source:     r.Source,
 					Overhead:   dur,
 					HostnameID: r.HostID,
 					StartTime:  r.StartTime,
 					EndTime:    r.EndTime,
+					Line:       r.Line,
+					Offset:     r.Offset,
+					Intended:   r.Intended,
+					Started:    start,
+					Completed:  start.Add(dur),
 				}
 			}
 		}(time.Now())
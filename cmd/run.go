@@ -2,33 +2,84 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/lfordyce/tiger/internal/domain"
+	"github.com/lfordyce/tiger/pkg/consts"
 	"github.com/lfordyce/tiger/pkg/csv"
 	"github.com/lfordyce/tiger/pkg/postgres"
 	"github.com/lfordyce/tiger/pkg/queue"
 	"github.com/lfordyce/tiger/pkg/statistics"
 	"github.com/lfordyce/tiger/pkg/table"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"io"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 const (
 	hostnameHeader       = "HOSTNAME"
+	cellHeader           = "CELL"
 	totalCountNameHeader = "TOTAL_RUN"
 	totalTimeNameHeader  = "TOTAL_TIME"
 	minHeader            = "MIN"
 	maxHeader            = "MAX"
 	medianHeader         = "MEDIAN"
 	averageHeader        = "AVG"
+	deltaHeader          = "DELTA"
+	stdDevHeader         = "STDDEV"
+	cvHeader             = "CV"
+)
+
+const (
+	// targetOrderInterleaved executes every request against all targets before moving on to the next request.
+	targetOrderInterleaved = "interleaved"
+	// targetOrderPasses executes the whole request set against one target after the other.
+	targetOrderPasses = "passes"
+)
+
+const (
+	// bootstrapResamples is the number of resamples the confidence intervals are estimated from.
+	bootstrapResamples = 1000
+	// bootstrapSize caps the number of samples per resample, so that large runs don't resample every sample.
+	bootstrapSize = 10000
+	// bootstrapSeed makes the confidence intervals of a set of samples reproducible.
+	bootstrapSeed = 1
 )
 
 // StreamWrite provides write-only access to an domain.Sample object.
 type StreamWrite chan<- statistics.Sample
 
+// runMetadata describes how a benchmark was run.
+type runMetadata struct {
+	Version  string
+	Target   string
+	Workers  int
+	ConnMode postgres.ConnMode
+	ExecMode postgres.ExecMode
+	Prepared bool
+	MaxConns int32
+	MinConns int32
+	Started  time.Time
+}
+
+func (m runMetadata) fields() logrus.Fields {
+	return logrus.Fields{
+		"version":   m.Version,
+		"target":    m.Target,
+		"workers":   m.Workers,
+		"conn_mode": m.ConnMode,
+		"exec_mode": m.ExecMode,
+		"prepared":  m.Prepared,
+		"max_conns": m.MaxConns,
+		"min_conns": m.MinConns,
+		"started":   m.Started,
+	}
+}
+
 // cmdRun handles the `tiger run` sub-command
 type cmdRun struct {
 	gs *globalState
@@ -38,73 +89,138 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) error {
 	if len(args) < 1 {
 		return fmt.Errorf("tiger needs at least one argument to load the test")
 	}
-	var err error
-	config, err := getConfig(cmd.Flags())
+	opts, err := getRunOptions(cmd.Flags())
 	if err != nil {
 		return err
 	}
+	// schedule paces the requests at the rate, without a rate they are handed over as fast as the queue accepts them
+	var schedule *domain.Schedule
+	if opts.rate > 0 {
+		if schedule, err = domain.NewSchedule(opts.rate); err != nil {
+			return err
+		}
+	}
+	if opts.connMode == postgres.ConnModePool && int32(opts.Workers) > opts.pgconn.Pool.MaxConns {
+		c.gs.logger.WithFields(logrus.Fields{
+			"workers":        opts.Workers,
+			"pool_max_conns": opts.pgconn.Pool.MaxConns,
+		}).Warn("more workers than pooled connections, time spent waiting for a connection counts as query overhead")
+	}
 
-	pgconn, err := postgres.GetConfig(cmd.Flags())
-	if err != nil {
-		return fmt.Errorf("failed to parse postgres config cli flags: %w", err)
+	fmtProcess := opts.csv
+	fmtProcess.Source = args[0]
+	if fmtProcess.Source == "-" {
+		fmtProcess.Source = "stdin"
 	}
+	c.gs.logger.WithField("timestamps", fmtProcess.Timestamp).Info("timestamp interpretation")
 
-	fmtProcess, err := domain.GetCsvConfig(cmd.Flags())
-	if err != nil {
-		return fmt.Errorf("failed to parse csv config cli flags: %w", err)
+	output := opts.output
+	if output.maxWidth == 0 {
+		output.maxWidth = c.gs.stdOut.width()
 	}
+	output.color = c.gs.stdOut.isTTY && !c.gs.flags.noColor
 
 	globalCtx, globalCancel := context.WithCancel(c.gs.ctx)
 	defer globalCancel()
 
-	c.gs.logger.WithField("workers", config.Workers).Info("concurrent worker count")
+	descriptions := make([]string, 0, len(opts.targets))
+	for _, t := range opts.targets {
+		descriptions = append(descriptions, t.DB.Target())
+	}
+	meta := runMetadata{
+		Version:  consts.FullVersion(),
+		Target:   strings.Join(descriptions, ", "),
+		Workers:  opts.Workers,
+		ConnMode: opts.connMode,
+		ExecMode: opts.pgconn.Exec.Mode,
+		Prepared: opts.pgconn.Exec.Prepare,
+		MaxConns: opts.pgconn.Pool.MaxConns,
+		MinConns: opts.pgconn.Pool.MinConns,
+		Started:  time.Now(),
+	}
+	c.gs.logger.WithFields(meta.fields()).Info("run metadata")
 	file := stdinOrFile(args[0], c.gs.stdIn)
 
-	processes := new(sync.WaitGroup)
 	errCh := make(chan error, 1)
 	sampleCh := make(chan statistics.Sample, 10)
 
-	repo, closeFunc, err := pgconn.OpenConnection(globalCtx)
-	if err != nil {
-		c.gs.logger.WithError(err).Error("Unable to connect to database")
-		return err
+	handlers := make(map[string]workerHandlers, len(opts.targets))
+	for _, t := range opts.targets {
+		h, err := openHandlers(globalCtx, t.DB, opts.connMode, opts.Workers)
+		if err != nil {
+			c.gs.logger.WithError(err).WithField("target", t.Name).Error("Unable to connect to database")
+			return err
+		}
+		defer h.close()
+		handlers[t.Name] = h
+	}
+	// a worker added by resizing the dispatcher connects before it takes requests, so that connecting isn't
+	// measured as query latency, and gives its dedicated connections back once it is retired
+	opts.queue.OnStart = func(id int) {
+		for name, h := range handlers {
+			if err := h.start(id); err != nil {
+				c.gs.logger.WithError(err).WithFields(logrus.Fields{"target": name, "worker_id": id}).
+					Warn("failed to connect worker, its first request connects again")
+			}
+		}
+	}
+	opts.queue.OnRetire = func(id int) {
+		for _, h := range handlers {
+			h.release(id)
+		}
+	}
+	// every pass over the requests runs on its own dispatcher, draining it waits for all jobs of the pass
+	var qd queue.Dispatcher
+	startQueue := func(workers int) {
+		qd = queue.NewDispatcher(workers, opts.queue)
+		go qd.Run(globalCtx)
+	}
+	// stagesOver is closed once the last stage is over and the dispatcher was stopped
+	stagesOver := make(chan struct{})
+	stopped := func(err error) bool {
+		select {
+		case <-stagesOver:
+			return errors.Is(err, queue.ErrClosed)
+		default:
+			return false
+		}
+	}
+	drainQueue := func() error {
+		defer qd.Stop()
+		if err := qd.Drain(globalCtx); err != nil && !stopped(err) {
+			return fmt.Errorf("failed to finish queued requests: %w", err)
+		}
+		stats := qd.Stats()
+		entry := c.gs.logger.WithFields(logrus.Fields{
+			"max_depth": stats.MaxDepth,
+			"capacity":  stats.Capacity,
+			"dropped":   stats.Dropped,
+			"rejected":  stats.Rejected,
+		})
+		if stats.Dropped > 0 || stats.Rejected > 0 {
+			entry.Warn("queue was full, requests were left out of the benchmark")
+		} else {
+			entry.Info("queue stats")
+		}
+		return nil
 	}
-	qd := queue.NewDispatcher(config.Workers)
-	go qd.Run()
-
-	defer func() {
-		closeFunc()
-		qd.Stop()
-	}()
 
 	jq := &domain.QueueHandler{
-		QueueJobHandler: domain.QueueJobHandlerFunc(func(job *domain.QueueJob) {
-			// increment then WaitGroup when job is queued in dispatcher
-			processes.Add(1)
-			qd.Queue(job)
+		QueueJobHandler: domain.QueueJobHandlerFunc(func(job *domain.QueueJob) error {
+			// rejected requests are counted by the queue and reported once it is drained
+			if err := qd.Queue(job); err != nil && !errors.Is(err, queue.ErrFull) {
+				return err
+			}
+			return nil
 		}),
 		TaskHandler: domain.TaskHandlerFunc(func(request domain.Request, u int) error {
-			// decrements the WaitGroup when job is finished executing
-			defer processes.Done()
-			if _, err := LogDurationHandler(repo, u, c.gs.logger, sampleCh).Process(request); err != nil {
+			handler := handlers[request.Target].handler(u)
+			if _, err := LogDurationHandler(handler, u, c.gs.logger, sampleCh).Process(request); err != nil {
 				return err
 			}
 			return nil
 		}),
-	}
-
-	elapsed := func() func() time.Duration {
-		start := time.Now()
-		return func() time.Duration {
-			return time.Since(start)
-		}
-	}()
-
-	fmtProcess.Run(csv.WithIoReader(file), jq, c.gs.logger, errCh)
-	err = <-errCh
-	if err != nil {
-		c.gs.logger.WithError(err).Error("csv processing failed")
-		return err
+		Schedule: schedule,
 	}
 
 	var local sync.WaitGroup
@@ -118,75 +234,270 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) error {
 		}
 		local.Done()
 	}()
-	processes.Wait()
+
+	elapsed := func() func() time.Duration {
+		start := time.Now()
+		return func() time.Duration {
+			return time.Since(start)
+		}
+	}()
+
+	durations := make(map[string]time.Duration, len(opts.targets))
+	var rampStart time.Time
+	if opts.order == targetOrderPasses {
+		// every target gets a pass over the whole request set, so the input has to be buffered
+		var requests []domain.Request
+		fmtProcess.Run(csv.WithIoReader(file), domain.TaskHandlerFunc(func(r domain.Request, _ int) error {
+			requests = append(requests, r)
+			return nil
+		}), c.gs.logger, errCh)
+		if err := <-errCh; err != nil {
+			c.gs.logger.WithError(err).Error("csv processing failed")
+			return err
+		}
+		for _, t := range opts.targets {
+			pass := time.Now()
+			startQueue(opts.Workers)
+			if schedule != nil {
+				// every pass starts on time, the drain of the previous pass isn't a delay of its first requests
+				schedule.Reset()
+			}
+			for _, r := range requests {
+				r.Target = t.Name
+				for _, variant := range opts.matrix.Expand(r) {
+					if err := jq.Process(variant, 0); err != nil {
+						qd.Stop()
+						return err
+					}
+				}
+			}
+			if err := drainQueue(); err != nil {
+				return err
+			}
+			durations[t.Name] = time.Since(pass)
+			c.gs.logger.WithFields(logrus.Fields{"target": t.Name, "elapsed": durations[t.Name]}).Info("pass finished")
+		}
+	} else {
+		if len(opts.stages) > 0 {
+			rampStart = time.Now()
+			_, workers := stageAt(opts.stages, 0)
+			startQueue(workers)
+			rampCtx, rampCancel := context.WithCancel(globalCtx)
+			defer rampCancel()
+			go rampStages(rampCtx, qd, opts.stages, rampStart, stagesOver, c.gs.logger)
+		} else {
+			startQueue(opts.Workers)
+		}
+		fmtProcess.Run(csv.WithIoReader(file), domain.TaskHandlerFunc(func(r domain.Request, n int) error {
+			for _, t := range opts.targets {
+				r.Target = t.Name
+				for _, variant := range opts.matrix.Expand(r) {
+					if err := jq.Process(variant, n); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}), c.gs.logger, errCh)
+		if err := <-errCh; err != nil && !stopped(err) {
+			qd.Stop()
+			c.gs.logger.WithError(err).Error("csv processing failed")
+			return err
+		}
+		if err := drainQueue(); err != nil {
+			return err
+		}
+	}
 	// signals that all events have been executed by the worker pool
 	finished := elapsed()
 
 	close(sampleCh)
 	local.Wait()
 
+	if len(opts.stages) > 0 {
+		assignStages(samples, opts.stages, rampStart)
+	}
 	c.gs.logger.WithField("total", len(samples)).Info("total results collected")
 	c.gs.logger.WithField("elapsed", finished).Info("execution time of all jobs")
+	c.gs.logger.Info("BENCHMARK STATISTICS")
 
-	collection := make(map[string]statistics.GroupedSample)
+	byTarget := make(map[string][]statistics.Sample, len(opts.targets))
 	for _, s := range samples {
-		collection[s.HostnameID] = statistics.GroupedSample{
-			HostnameID: s.HostnameID,
-			Elapsed:    append(collection[s.HostnameID].Elapsed, s.Elapsed),
-			Overhead:   append(collection[s.HostnameID].Overhead, s.Overhead),
+		byTarget[s.Target] = append(byTarget[s.Target], s)
+	}
+	sections := resultSections(opts, byTarget, durations, finished)
+	for i := range sections {
+		sections[i].table.HideColumns(output.hidden...)
+		if sections[i].table.Style == nil {
+			sections[i].table.Style = opts.stats.thresholds.style
+		}
+	}
+	if err := renderResults(meta, sections, output, c.gs.stdOut); err != nil {
+		return err
+	}
+
+	if opts.intervalOut != "" {
+		if err := writeSeries(opts.intervalOut, opts.targets, byTarget, opts.interval); err != nil {
+			return err
 		}
+		c.gs.logger.WithField("file", opts.intervalOut).Info("latency series written")
 	}
 
-	var dStats []dataStats
-	for k, v := range collection {
-		dStats = append(dStats, dataStats{
-			hostName:  k,
-			totalRun:  len(v.Elapsed),
-			totalTime: statistics.Sum(v.Elapsed),
-			minTime:   statistics.Min(v.Elapsed),
-			maxTime:   statistics.Max(v.Elapsed),
-			median:    statistics.Median(v.Elapsed),
-			average:   statistics.Mean(v.Elapsed),
+	if opts.htmlReport != "" {
+		r := buildReport(meta, cmd.Flags(), opts.targets, byTarget, sections, opts.interval)
+		if err := writeReport(opts.htmlReport, r); err != nil {
+			return err
+		}
+		c.gs.logger.WithField("file", opts.htmlReport).Info("html report written")
+	}
+	return nil
+}
+
+// resultSections builds the result tables of every target. durations are the elapsed times of the passes over
+// the targets, targets without a pass of their own ran for finished.
+func resultSections(
+	opts runOptions, byTarget map[string][]statistics.Sample, durations map[string]time.Duration,
+	finished time.Duration,
+) []resultSection {
+	var sections []resultSection
+	for _, t := range opts.targets {
+		heading := ""
+		if len(opts.targets) > 1 {
+			heading = fmt.Sprintf(" (TARGET %s)", t.Name)
+		}
+		duration, ok := durations[t.Name]
+		if !ok {
+			duration = finished
+		}
+
+		for _, dims := range opts.groupings {
+			sections = append(sections, resultSection{
+				target:  t.Name,
+				title:   fmt.Sprintf("BENCHMARK STATISTICS BY %s%s", groupTitle(dims), heading),
+				table:   stateTable(dims, groupStats(byTarget[t.Name], dims), opts.stats),
+				summary: len(dims) == 1 && dims[0].name == hostnameDimension.name,
+			})
+		}
+		if opts.interval > 0 {
+			sections = append(sections, resultSection{
+				target: t.Name,
+				title:  fmt.Sprintf("LATENCY OVER TIME (%s INTERVALS)%s", opts.interval, heading),
+				table:  seriesTable(statistics.Series(byTarget[t.Name], opts.interval)),
+			})
+		}
+		sections = append(sections, resultSection{
+			target: t.Name,
+			title:  fmt.Sprintf("LATENCY DISTRIBUTION%s", heading),
+			table:  latencyTable(byTarget[t.Name]),
+		})
+		if opts.outliers != outliersNone {
+			sections = append(sections, resultSection{
+				target: t.Name,
+				title:  fmt.Sprintf("OUTLIERS (%s)%s", strings.ToUpper(opts.outliers), heading),
+				table:  outliersTable(findOutliers(byTarget[t.Name], opts.outliers)),
+			})
+		}
+		sections = append(sections, stageSections(opts.stages, byTarget[t.Name], t.Name, heading, opts.stats)...)
+		sections = append(sections, resultSection{
+			target:  t.Name,
+			title:   fmt.Sprintf("TOTAL BENCHMARK STATISTICS%s", heading),
+			table:   totalTable(byTarget[t.Name], duration, opts.stats),
+			summary: true,
+		})
+	}
+	if len(opts.targets) > 1 {
+		sections = append(sections, resultSection{
+			title:   fmt.Sprintf("MEDIAN DELTA BY HOSTNAME (BASELINE %s)", opts.targets[0].Name),
+			table:   deltaTable(opts.targets, byTarget),
+			summary: true,
 		})
 	}
-	c.gs.logger.Info("BENCHMARK STATISTICS")
-	fmt.Fprint(c.gs.stdOut, "BENCHMARK STATISTICS BY HOSTNAME:\n")
-	renderState(dStats, c.gs.stdOut)
-	fmt.Fprint(c.gs.stdOut, "\n\n")
 
-	var final []float64
-	for _, v := range samples {
-		final = append(final, v.Elapsed)
+	return sections
+}
+
+// workerHandlers are the handlers the workers execute the requests of a database with.
+type workerHandlers struct {
+	handler func(id int) domain.Handler
+	// start prepares the resources of a worker before it takes requests, release frees them once it is retired
+	start   func(id int) error
+	release func(id int)
+	close   func()
+}
+
+// openHandlers connects to the database and returns the handlers of the workers.
+func openHandlers(
+	ctx context.Context, pgconn *postgres.DBDetails, mode postgres.ConnMode, workers int,
+) (workerHandlers, error) {
+	if mode == postgres.ConnModePerWorker {
+		conns, closeFunc, err := pgconn.OpenPerWorker(ctx, workers)
+		if err != nil {
+			return workerHandlers{}, err
+		}
+		return workerHandlers{handler: conns.Worker, start: conns.Connect, release: conns.Release, close: closeFunc}, nil
 	}
 
-	finalOutput := buildFinalTable()
-	finalOutput.Data = []table.Row{}
-	finalOutput.Data = append(finalOutput.Data, []string{
-		fmt.Sprint(len(samples)),
-		fmt.Sprintf("%s", finished),
-		fmt.Sprintf("%.4fms", statistics.Min(final)),
-		fmt.Sprintf("%.4fms", statistics.Max(final)),
-		fmt.Sprintf("%.4fms", statistics.Median(final)),
-		fmt.Sprintf("%.4fms", statistics.Mean(final)),
-	})
-	fmt.Fprint(c.gs.stdOut, "TOTAL BENCHMARK STATISTICS:\n")
-	finalOutput.Render(c.gs.stdOut)
-	return nil
+	repo, closeFunc, err := pgconn.OpenConnection(ctx)
+	if err != nil {
+		return workerHandlers{}, err
+	}
+	return workerHandlers{
+		handler: func(int) domain.Handler { return repo },
+		start:   func(int) error { return nil },
+		release: func(int) {},
+		close:   closeFunc,
+	}, nil
 }
 
 func (c *cmdRun) flagSet() *pflag.FlagSet {
 	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
 	flags.SortFlags = false
 	flags.IntP("workers", "w", 3, "Number of workers for concurrency work.")
-	flags.String("user", "postgres", "Postgres user")
-	flags.String("password", "password", "Postgres password")
-	flags.String("host", "localhost", "Postgres hostname")
-	flags.Uint16("port", 5432, "Postgres port")
-	flags.String("database", "homework", "Postgres database name")
-	flags.String("csv-host-hdr", "hostname", "The name of the CSV host id field")
-	flags.String("csv-start-hdr", "start_time", "The name of the CSV start time field")
-	flags.String("csv-end-hdr", "end_time", "The name of the CSV end time field")
-	flags.String("csv-ts-fmt", "2006-01-02 15:04:05", "The go timestamp format of the CSV timestamp field")
+	flags.String("stages", "", "Comma separated load stages in the form duration:workers, e.g. 30s:1,2m:8,30s:0. "+
+		"The number of workers moves linearly to the count of each stage over its duration, starting at one "+
+		"worker, and the run ends after the last stage")
+	flags.Float64("rate", 0, "Requests per second handed to the workers, across targets and matrix cells. Requests "+
+		"are due at a fixed interval and the schedule delay and corrected latency are measured from the time they "+
+		"were due. Disabled by default, requests are then handed over as fast as the queue accepts them and the "+
+		"corrected latency is estimated from the median response time of every worker")
+	flags.AddFlagSet(postgresFlagSet())
+	flags.StringArray("target", nil, "Database to benchmark in the form name=dsn, repeat to compare several "+
+		"databases. Defaults to the database given by the connection flags")
+	flags.String("target-order", targetOrderInterleaved, "How requests are spread over several targets, "+
+		"possible values are interleaved,passes")
+	flags.StringArray("matrix", nil, "Query variant to benchmark in the form key=value1,value2, repeat to "+
+		"combine parameters. Possible keys are bucket (time_bucket width) and agg (aggregate function)")
+	flags.StringArray("group-by", []string{"hostname"}, "Comma separated dimensions to break statistics down by, "+
+		"repeat for several tables. Possible values are hostname,worker,window-length,start-hour,source-file,"+
+		"target,cell,backend,stage")
+	flags.Float64("ci", 0, "Confidence level in percent, e.g. 95, adds standard deviation, coefficient of variation "+
+		"and bootstrapped confidence intervals of mean and median to the statistics. Every table row resamples up to "+
+		"10000 of its samples 1000 times per interval, about a quarter second of CPU time for a row of 10000 or more "+
+		"samples. Disabled by default")
+	flags.String("sort", "", "Comma separated columns to sort the statistics tables by in the form "+
+		"column[:asc|desc], e.g. max:desc. Defaults to the group-by columns")
+	flags.String("trim", "", "Percentage of the fastest and of the slowest samples to leave out of the trimmed "+
+		"MAX and AVG, e.g. 1%. Disabled by default")
+	flags.String("trim-mode", trimModeTrim, "How --trim treats the tails, possible values are trim,winsorize")
+	flags.String("outliers", outliersNone, "List samples that are outliers of their hostname, possible values "+
+		"are none,iqr,mad")
+	flags.Duration("interval", 0, "Width of the wall-clock intervals to report latency over time for, e.g. 1s. "+
+		"Disabled by default")
+	flags.String("interval-out", "", "Export the latency series of --interval as csv to the given file")
+	flags.String("summary-format", table.FormatText, "Format of the result tables, possible values are "+
+		strings.Join(table.Formats, ","))
+	flags.Int("max-width", 0, "Maximum width of text and box tables, defaults to the width of the terminal. "+
+		"A negative width does not limit the tables")
+	flags.String("overflow", overflowEllipsis, "How text and box tables show values wider than their column, "+
+		"possible values are ellipsis,wrap")
+	flags.Duration("warn-threshold", 0, "Highlight latencies above the threshold on colour terminals, e.g. 50ms")
+	flags.Duration("crit-threshold", 0, "Highlight latencies above the threshold as critical on colour terminals, "+
+		"e.g. 200ms")
+	flags.StringSlice("hide-columns", nil, "Comma separated headers of the columns to leave out of the result tables")
+	flags.String("html-report", "", "Write a self-contained html report with charts of the results to the given file")
+	flags.AddFlagSet(queueFlagSet())
+	flags.AddFlagSet(execFlagSet())
+	flags.AddFlagSet(csvFlagSet())
 
 	return flags
 }
@@ -208,104 +519,217 @@ func getCmdRun(gs *globalState) *cobra.Command {
 	return runCmd
 }
 
+// totalTable summarises all samples of a target.
+func totalTable(samples []statistics.Sample, finished time.Duration, opts statsOptions) table.Table {
+	var final []float64
+	for _, v := range samples {
+		final = append(final, v.Elapsed)
+	}
+
+	finalOutput := buildFinalTable()
+	finalOutput.Columns = append(finalOutput.Columns, optionalColumns(opts)...)
+	finalOutput.Data = []table.Row{}
+	finalOutput.Data = append(finalOutput.Data, append([]string{
+		fmt.Sprint(len(samples)),
+		fmt.Sprintf("%s", finished),
+		formatLatency(statistics.Min(final)),
+		formatLatency(statistics.Max(final)),
+		formatLatency(statistics.Median(final)),
+		formatLatency(statistics.Mean(final)),
+	}, optionalRow(final, opts)...))
+	return finalOutput
+}
+
+// deltaTable compares the median of every hostname on each target against the first target.
+func deltaTable(targets []postgres.Target, byTarget map[string][]statistics.Sample) table.Table {
+	medians := make(map[string]map[string]float64, len(targets))
+	hosts := make(map[string]bool)
+	for _, t := range targets {
+		medians[t.Name] = make(map[string]float64)
+		for _, s := range hostStats(byTarget[t.Name]) {
+			medians[t.Name][s.keys[0]] = s.median
+			hosts[s.keys[0]] = true
+		}
+	}
+
+	columns := []table.Column{{Header: hostnameHeader, Width: 7, Flexible: true, LeftAlign: true}}
+	for i, t := range targets {
+		columns = append(columns, latencyColumn(strings.ToUpper(t.Name)+"_"+medianHeader))
+		if i > 0 {
+			columns = append(columns, table.NewColumn(strings.ToUpper(t.Name)+"_"+deltaHeader).WithType(table.TypeFloat))
+		}
+	}
+	t := table.NewTable(columns, []table.Row{})
+	t.Sort = table.SortBy(false, 0)
+
+	base := medians[targets[0].Name]
+	for host := range hosts {
+		row := []string{host}
+		for i, target := range targets {
+			median, ok := medians[target.Name][host]
+			if !ok {
+				row = append(row, "-")
+				if i > 0 {
+					row = append(row, "-")
+				}
+				continue
+			}
+			row = append(row, formatLatency(median))
+			if i > 0 {
+				if b, ok := base[host]; ok && b != 0 {
+					row = append(row, fmt.Sprintf("%+.2f%%", (median-b)/b*100))
+				} else {
+					row = append(row, "-")
+				}
+			}
+		}
+		t.Data = append(t.Data, row)
+	}
+	return t
+}
+
 type dataStats struct {
-	hostName  string
+	keys      []string
 	totalRun  int
 	totalTime float64
 	minTime   float64
 	maxTime   float64
 	median    float64
 	average   float64
+	values    []float64
 }
 
-func renderState(statuses []dataStats, w io.Writer) {
-	t := buildTable()
+// stateTable lists the statistics of every group.
+func stateTable(dims []dimension, statuses []dataStats, opts statsOptions) table.Table {
+	t := buildTable(dims)
+	t.Columns = append(t.Columns, optionalColumns(opts)...)
+	if opts.sort != "" {
+		// the sort spec is validated by validateSort, groups with equal values stay sorted by their keys
+		keys, _ := table.ParseSort(opts.sort, t.Columns)
+		t.Sort = append(keys, t.Sort...)
+	}
 	t.Data = []table.Row{}
 	for _, status := range statuses {
 		status := status
-		t.Data = append(t.Data, statsToTableRow(status))
+		t.Append(append(statsToTableRow(status), optionalRow(status.values, opts)...), statsToRawRow(status))
 	}
-	t.Render(w)
+	// the median follows the key columns and TOTAL_RUN, TOTAL_TIME, MIN and MAX
+	markSlowest(&t, opts.thresholds, len(dims), len(dims)+4)
+	return t
+}
+
+// statsToRawRow returns the raw values behind statsToTableRow.
+func statsToRawRow(status dataStats) []table.Value {
+	raw := make([]table.Value, 0, len(status.keys)+6)
+	for _, k := range status.keys {
+		raw = append(raw, k)
+	}
+	return append(raw, status.totalRun, millis(status.totalTime), millis(status.minTime), millis(status.maxTime),
+		millis(status.median), millis(status.average))
 }
 
 func statsToTableRow(status dataStats) []string {
-	return []string{
-		status.hostName,
+	return append(append([]string{}, status.keys...),
 		fmt.Sprint(status.totalRun),
-		fmt.Sprintf("%.4fms", status.totalTime),
-		fmt.Sprintf("%.4fms", status.minTime),
-		fmt.Sprintf("%.4fms", status.maxTime),
-		fmt.Sprintf("%.4fms", status.median),
-		fmt.Sprintf("%.4fms", status.average),
+		formatLatency(status.totalTime),
+		formatLatency(status.minTime),
+		formatLatency(status.maxTime),
+		formatLatency(status.median),
+		formatLatency(status.average),
+	)
+}
+
+// varianceColumns are the optional columns describing the spread of the measurements, added when a confidence
+// level is requested with --ci.
+func varianceColumns(ci float64) []table.Column {
+	if ci <= 0 {
+		return nil
+	}
+	level := strconv.FormatFloat(ci, 'f', -1, 64)
+	return []table.Column{
+		latencyColumn(stdDevHeader),
+		table.NewColumn(cvHeader).WithType(table.TypeFloat),
+		table.NewColumn(averageHeader + "_CI" + level),
+		table.NewColumn(medianHeader + "_CI" + level),
 	}
 }
 
-func buildTable() table.Table {
-	columns := []table.Column{
-		{
-			Header:    hostnameHeader,
+// varianceRow computes the values of the varianceColumns, the confidence intervals are bootstrapped.
+func varianceRow(values []float64, ci float64) []string {
+	if ci <= 0 {
+		return nil
+	}
+	meanLo, meanHi := statistics.Bootstrap(
+		values, statistics.Mean[float64], ci, bootstrapResamples, bootstrapSize, bootstrapSeed,
+	)
+	medianLo, medianHi := statistics.Bootstrap(
+		values, statistics.Median[float64], ci, bootstrapResamples, bootstrapSize, bootstrapSeed,
+	)
+	return []string{
+		formatLatency(statistics.StdDev(values)),
+		fmt.Sprintf("%.2f%%", statistics.CV(values)*100),
+		formatLatency(meanLo) + ".." + formatLatency(meanHi),
+		formatLatency(medianLo) + ".." + formatLatency(medianHi),
+	}
+}
+
+func buildTable(dims []dimension) table.Table {
+	var columns []table.Column
+	sortBy := make([]int, 0, len(dims))
+	for i, d := range dims {
+		columns = append(columns, table.Column{
+			Header:    d.header,
 			Width:     7,
 			Flexible:  true,
 			LeftAlign: true,
-		},
-		{
-			Header: totalCountNameHeader,
-			Width:  9,
-		},
-		{
-			Header: totalTimeNameHeader,
-			Width:  11,
-		},
-		{
-			Header: minHeader,
-			Width:  11,
-		},
-		{
-			Header: maxHeader,
-			Width:  11,
-		},
-		{
-			Header: medianHeader,
-			Width:  11,
-		},
-		{
-			Header: averageHeader,
-			Width:  11,
-		},
+			Type:      d.typ,
+		})
+		sortBy = append(sortBy, i)
 	}
+	columns = append(columns, statColumns()...)
 	t := table.NewTable(columns, []table.Row{})
-	t.Sort = []int{0}
+	t.Sort = table.SortBy(false, sortBy...)
 	return t
 }
 
 func buildFinalTable() table.Table {
-	columns := []table.Column{
+	t := table.NewTable(statColumns(), []table.Row{})
+	t.Sort = table.SortBy(false, 0)
+	return t
+}
+
+// statColumns are the columns of the statistics of a group of samples.
+func statColumns() []table.Column {
+	return []table.Column{
 		{
 			Header: totalCountNameHeader,
 			Width:  9,
+			Type:   table.TypeInt,
 		},
 		{
 			Header: totalTimeNameHeader,
 			Width:  11,
+			Type:   table.TypeDuration,
 		},
 		{
 			Header: minHeader,
 			Width:  11,
+			Type:   table.TypeDuration,
 		},
 		{
 			Header: maxHeader,
 			Width:  11,
+			Type:   table.TypeDuration,
 		},
 		{
 			Header: medianHeader,
 			Width:  11,
+			Type:   table.TypeDuration,
 		},
 		{
 			Header: averageHeader,
 			Width:  11,
+			Type:   table.TypeDuration,
 		},
 	}
-	t := table.NewTable(columns, []table.Row{})
-	t.Sort = []int{0}
-	return t
 }
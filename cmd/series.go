@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	stdcsv "encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lfordyce/tiger/pkg/postgres"
+	"github.com/lfordyce/tiger/pkg/statistics"
+	"github.com/lfordyce/tiger/pkg/table"
+)
+
+// seriesTable lists the latency of each interval of the run.
+func seriesTable(series []statistics.Interval) table.Table {
+	t := table.NewTable([]table.Column{
+		table.NewColumn("OFFSET").WithLeftAlign(),
+		table.NewColumn("TIME").WithLeftAlign(),
+		table.NewColumn("COUNT").WithType(table.TypeInt),
+		table.NewColumn("QPS").WithType(table.TypeFloat),
+		latencyColumn("P50"),
+		latencyColumn("P95"),
+		latencyColumn(maxHeader),
+	}, []table.Row{})
+
+	for _, i := range series {
+		t.Data = append(t.Data, []string{
+			"+" + i.Start.Sub(series[0].Start).String(),
+			i.Start.Format("15:04:05.000"),
+			fmt.Sprint(i.Count),
+			fmt.Sprintf("%.2f", i.QPS),
+			formatLatency(i.P50),
+			formatLatency(i.P95),
+			formatLatency(i.Max),
+		})
+	}
+	return t
+}
+
+// writeSeries exports the latency series of every target as csv file, so spikes can be correlated with server
+// events.
+func writeSeries(
+	path string, targets []postgres.Target, byTarget map[string][]statistics.Sample, width time.Duration,
+) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cmd.writeSeries: failed to create series file %w", err)
+	}
+	defer f.Close()
+
+	w := stdcsv.NewWriter(f)
+	if err := w.Write([]string{"target", "start", "count", "qps", "p50_ms", "p95_ms", "max_ms"}); err != nil {
+		return fmt.Errorf("cmd.writeSeries: failed to write header %w", err)
+	}
+	for _, t := range targets {
+		for _, i := range statistics.Series(byTarget[t.Name], width) {
+			err := w.Write([]string{
+				t.Name,
+				i.Start.Format(time.RFC3339Nano),
+				strconv.Itoa(i.Count),
+				strconv.FormatFloat(i.QPS, 'f', 2, 64),
+				strconv.FormatFloat(i.P50, 'f', 4, 64),
+				strconv.FormatFloat(i.P95, 'f', 4, 64),
+				strconv.FormatFloat(i.Max, 'f', 4, 64),
+			})
+			if err != nil {
+				return fmt.Errorf("cmd.writeSeries: failed to write interval %w", err)
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("cmd.writeSeries: failed to flush series %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lfordyce/tiger/pkg/queue"
+	"github.com/lfordyce/tiger/pkg/statistics"
+)
+
+// rampInterval is how often the worker count is adjusted while moving through the stages.
+const rampInterval = 100 * time.Millisecond
+
+// stage is a step of a load profile, the worker count moves linearly from the count at the end of the previous
+// stage to workers over the duration. The first stage starts at a single worker.
+type stage struct {
+	duration time.Duration
+	workers  int
+}
+
+// parseStages parses a comma separated list of stages in the form duration:workers, e.g. 30s:1,2m:8,30s:0.
+func parseStages(spec string) ([]stage, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	var stages []stage
+	for _, part := range strings.Split(spec, ",") {
+		duration, workers, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid stage '%s', expected duration:workers, e.g. 30s:4", part)
+		}
+		d, err := time.ParseDuration(duration)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid duration of stage '%s', expected a positive duration, e.g. 30s", part)
+		}
+		n, err := strconv.Atoi(workers)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid workers of stage '%s', expected a number of at least 0", part)
+		}
+		stages = append(stages, stage{duration: d, workers: n})
+	}
+	return stages, nil
+}
+
+// maxStageWorkers is the highest worker count of the stages, workers and connections are sized for it.
+func maxStageWorkers(stages []stage) int {
+	max := 1
+	for _, s := range stages {
+		if s.workers > max {
+			max = s.workers
+		}
+	}
+	return max
+}
+
+// stageAt returns the number of the stage, starting at 1, the offset since the start of the first stage falls into
+// and the worker count at the offset. Both are 0 once all stages are over.
+func stageAt(stages []stage, offset time.Duration) (int, int) {
+	from := 1
+	for i, s := range stages {
+		if offset < s.duration {
+			progress := float64(offset) / float64(s.duration)
+			return i + 1, from + int(math.Round(float64(s.workers-from)*progress))
+		}
+		offset -= s.duration
+		from = s.workers
+	}
+	return 0, 0
+}
+
+// rampStages resizes the dispatcher along the stages started at start. Once the last stage is over it closes over
+// and stops the dispatcher, requests that were not executed yet are left out. It returns early if the context is
+// done or the dispatcher was stopped because all requests were executed.
+func rampStages(
+	ctx context.Context, qd queue.Dispatcher, stages []stage, start time.Time, over chan<- struct{},
+	logger *logrus.Logger,
+) {
+	ticker := time.NewTicker(rampInterval)
+	defer ticker.Stop()
+
+	current, workers := 0, -1
+	for {
+		number, n := stageAt(stages, time.Since(start))
+		if number == 0 {
+			logger.Info("all stages finished, requests that were not executed yet are skipped")
+			close(over)
+			qd.Stop()
+			return
+		}
+		if number != current {
+			current = number
+			logger.WithFields(logrus.Fields{
+				"stage":    number,
+				"duration": stages[number-1].duration,
+				"workers":  stages[number-1].workers,
+			}).Info("stage started")
+		}
+		if n != workers {
+			if err := qd.Resize(n); err != nil {
+				return
+			}
+			workers = n
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// assignStages sets the stage every sample started in.
+func assignStages(samples []statistics.Sample, stages []stage, start time.Time) {
+	for i := range samples {
+		samples[i].Stage, _ = stageAt(stages, samples[i].Started.Sub(start))
+	}
+}
+
+// stageSections reports the overall statistics of every stage a sample started in.
+func stageSections(
+	stages []stage, samples []statistics.Sample, target, heading string, opts statsOptions,
+) []resultSection {
+	byStage := make([][]statistics.Sample, len(stages))
+	for _, s := range samples {
+		if s.Stage > 0 {
+			byStage[s.Stage-1] = append(byStage[s.Stage-1], s)
+		}
+	}
+
+	var sections []resultSection
+	from := 1
+	for i, s := range stages {
+		if len(byStage[i]) > 0 {
+			sections = append(sections, resultSection{
+				target: target,
+				title: fmt.Sprintf("STAGE %d STATISTICS (%s, %d → %d WORKERS)%s", i+1, s.duration, from, s.workers,
+					heading),
+				table:   totalTable(byStage[i], s.duration, opts),
+				summary: true,
+			})
+		}
+		from = s.workers
+	}
+	return sections
+}
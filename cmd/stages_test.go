@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lfordyce/tiger/pkg/queue"
+	"github.com/lfordyce/tiger/pkg/statistics"
+)
+
+func TestParseStages(t *testing.T) {
+	cases := [...]struct {
+		spec    string
+		want    []stage
+		wantErr string
+	}{
+		{spec: "", want: nil},
+		{spec: " ", want: nil},
+		{spec: "30s:4", want: []stage{{30 * time.Second, 4}}},
+		{spec: "30s:1, 2m:8,30s:0", want: []stage{{30 * time.Second, 1}, {2 * time.Minute, 8}, {30 * time.Second, 0}}},
+		{spec: "30s", wantErr: "invalid stage '30s', expected duration:workers"},
+		{spec: "30s:4,", wantErr: "invalid stage ''"},
+		{spec: "thirty:4", wantErr: "invalid duration of stage 'thirty:4'"},
+		{spec: "0s:4", wantErr: "invalid duration of stage '0s:4'"},
+		{spec: "-1s:4", wantErr: "invalid duration of stage '-1s:4'"},
+		{spec: "30s:-1", wantErr: "invalid workers of stage '30s:-1'"},
+		{spec: "30s:four", wantErr: "invalid workers of stage '30s:four'"},
+	}
+	for _, tst := range cases {
+		stages, err := parseStages(tst.spec)
+		if tst.wantErr != "" {
+			assert.ErrorContains(t, err, tst.wantErr, tst.spec)
+			continue
+		}
+		require.NoError(t, err, tst.spec)
+		assert.Equal(t, tst.want, stages, tst.spec)
+	}
+}
+
+func TestMaxStageWorkers(t *testing.T) {
+	assert.Equal(t, 1, maxStageWorkers(nil))
+	assert.Equal(t, 1, maxStageWorkers([]stage{{time.Second, 0}}))
+	assert.Equal(t, 8, maxStageWorkers([]stage{{time.Second, 2}, {time.Second, 8}, {time.Second, 0}}))
+}
+
+func TestStageAt(t *testing.T) {
+	stages := []stage{{10 * time.Second, 5}, {20 * time.Second, 5}, {10 * time.Second, 0}}
+	cases := [...]struct {
+		offset  time.Duration
+		stage   int
+		workers int
+	}{
+		// the first stage ramps up from a single worker
+		{0, 1, 1},
+		{time.Second, 1, 1},
+		{5 * time.Second, 1, 3},
+		{10*time.Second - 1, 1, 5},
+		// a stage starts with the workers the previous one ended with
+		{10 * time.Second, 2, 5},
+		{29 * time.Second, 2, 5},
+		// and ramps down to no workers at all
+		{30 * time.Second, 3, 5},
+		{34 * time.Second, 3, 3},
+		{40*time.Second - 1, 3, 0},
+		// everything is over after the last stage
+		{40 * time.Second, 0, 0},
+		{time.Hour, 0, 0},
+	}
+	for _, tst := range cases {
+		number, workers := stageAt(stages, tst.offset)
+		assert.Equal(t, tst.stage, number, "stage at %s", tst.offset)
+		assert.Equal(t, tst.workers, workers, "workers at %s", tst.offset)
+	}
+
+	number, workers := stageAt(nil, 0)
+	assert.Equal(t, 0, number)
+	assert.Equal(t, 0, workers)
+}
+
+// fakeDispatcher records how rampStages resizes and stops it.
+type fakeDispatcher struct {
+	queue.Dispatcher
+	over <-chan struct{}
+
+	mu      sync.Mutex
+	resizes []int
+	stopped bool
+	// overOnStop tells whether over was closed by the time Stop was called
+	overOnStop bool
+}
+
+func (f *fakeDispatcher) Resize(n int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stopped {
+		return queue.ErrClosed
+	}
+	f.resizes = append(f.resizes, n)
+	return nil
+}
+
+func (f *fakeDispatcher) Stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	select {
+	case <-f.over:
+		f.overOnStop = true
+	default:
+	}
+	f.stopped = true
+}
+
+func (f *fakeDispatcher) state() ([]int, bool, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.resizes...), f.stopped, f.overOnStop
+}
+
+// ramp runs rampStages in the background, the returned channel is closed once it returned.
+func ramp(ctx context.Context, qd *fakeDispatcher, stages []stage, start time.Time, over chan struct{}) chan struct{} {
+	logger, _ := test.NewNullLogger()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rampStages(ctx, qd, stages, start, over, logger)
+	}()
+	return done
+}
+
+func TestRampStagesResizes(t *testing.T) {
+	over := make(chan struct{})
+	qd := &fakeDispatcher{over: over}
+	ctx, cancel := context.WithCancel(context.Background())
+	// halfway through the first stage, the worker count doesn't change within the test
+	done := ramp(ctx, qd, []stage{{time.Hour, 5}, {time.Hour, 0}}, time.Now().Add(-30*time.Minute), over)
+
+	require.Eventually(t, func() bool {
+		resizes, _, _ := qd.state()
+		return len(resizes) > 0
+	}, time.Second, time.Millisecond)
+	time.Sleep(3 * rampInterval)
+	cancel()
+	<-done
+
+	resizes, stopped, _ := qd.state()
+	assert.Equal(t, []int{3}, resizes, "the dispatcher is only resized when the worker count changes")
+	assert.False(t, stopped)
+	select {
+	case <-over:
+		t.Fatal("over closed before the last stage is over")
+	default:
+	}
+}
+
+func TestRampStagesOver(t *testing.T) {
+	over := make(chan struct{})
+	qd := &fakeDispatcher{over: over}
+	done := ramp(context.Background(), qd, []stage{{2 * rampInterval, 2}}, time.Now(), over)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("rampStages didn't return after the last stage")
+	}
+	resizes, stopped, overOnStop := qd.state()
+	require.NotEmpty(t, resizes)
+	assert.Equal(t, 1, resizes[0], "the first stage starts at a single worker")
+	assert.True(t, stopped)
+	// the run tells the ErrClosed of the stopped dispatcher from a failure by over
+	assert.True(t, overOnStop, "over must be closed before the dispatcher is stopped")
+}
+
+func TestRampStagesRequestsRunOut(t *testing.T) {
+	over := make(chan struct{})
+	qd := &fakeDispatcher{over: over}
+	// the csv ran out and the run drained and stopped the dispatcher before the stages are over
+	qd.Stop()
+	done := ramp(context.Background(), qd, []stage{{time.Hour, 5}}, time.Now(), over)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("rampStages didn't return after the dispatcher was stopped")
+	}
+	resizes, _, _ := qd.state()
+	assert.Empty(t, resizes)
+	select {
+	case <-over:
+		t.Fatal("over closed although the stages are not over")
+	default:
+	}
+}
+
+func TestAssignStages(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	stages := []stage{{10 * time.Second, 4}, {10 * time.Second, 0}}
+	samples := []statistics.Sample{
+		{HostnameID: "host_000000", Started: start, Elapsed: 1},
+		{HostnameID: "host_000000", Started: start.Add(10*time.Second - 1), Elapsed: 2},
+		{HostnameID: "host_000000", Started: start.Add(10 * time.Second), Elapsed: 3},
+		{HostnameID: "host_000000", Started: start.Add(20 * time.Second), Elapsed: 4},
+	}
+	assignStages(samples, stages, start)
+
+	got := make([]int, 0, len(samples))
+	for _, s := range samples {
+		got = append(got, s.Stage)
+	}
+	assert.Equal(t, []int{1, 1, 2, 0}, got)
+
+	sections := stageSections(stages, samples, "target", "", statsOptions{})
+	require.Len(t, sections, 2)
+	assert.Equal(t, "STAGE 1 STATISTICS (10s, 1 → 4 WORKERS)", sections[0].title)
+	assert.Equal(t, "STAGE 2 STATISTICS (10s, 4 → 0 WORKERS)", sections[1].title)
+	for _, s := range sections {
+		assert.Equal(t, "target", s.target)
+		assert.True(t, s.summary)
+	}
+
+	// stages without samples are left out, a sample started after the last stage is in none
+	stages = append(stages, stage{10 * time.Second, 2})
+	assignStages(samples[3:], stages, start)
+	sections = stageSections(stages, samples[3:], "target", " (TARGET target)", statsOptions{})
+	require.Len(t, sections, 1)
+	assert.Equal(t, "STAGE 3 STATISTICS (10s, 0 → 2 WORKERS) (TARGET target)", sections[0].title)
+}
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"context"
+	stdcsv "encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/lfordyce/tiger/internal/domain"
+	"github.com/lfordyce/tiger/pkg/consts"
+	"github.com/lfordyce/tiger/pkg/csv"
+	"github.com/lfordyce/tiger/pkg/postgres"
+	"github.com/lfordyce/tiger/pkg/queue"
+	"github.com/lfordyce/tiger/pkg/statistics"
+	"github.com/lfordyce/tiger/pkg/table"
+)
+
+// kneeDistance is how far above the straight line between the lowest and the highest concurrency, in fractions of
+// the throughput range, the throughput has to be for a concurrency level to be marked as knee.
+const kneeDistance = 0.05
+
+// cmdSweep handles the `tiger sweep` sub-command
+type cmdSweep struct {
+	gs *globalState
+}
+
+// sweepLevel is the outcome of executing the request set at one concurrency level.
+type sweepLevel struct {
+	workers  int
+	requests int
+	elapsed  time.Duration
+	samples  []statistics.Sample
+}
+
+// errors counts the requests that failed for good, retried requests that succeeded in the end are not counted.
+func (l sweepLevel) errors() int {
+	return l.requests - len(l.samples)
+}
+
+func (l sweepLevel) errorRate() float64 {
+	if l.requests == 0 {
+		return 0
+	}
+	return float64(l.errors()) / float64(l.requests)
+}
+
+// qps is the throughput of successful requests.
+func (l sweepLevel) qps() float64 {
+	if l.elapsed <= 0 {
+		return 0
+	}
+	return float64(len(l.samples)) / l.elapsed.Seconds()
+}
+
+func (l sweepLevel) percentile(p float64) float64 {
+	values := make([]float64, 0, len(l.samples))
+	for _, s := range l.samples {
+		values = append(values, s.Elapsed)
+	}
+	return statistics.Percentile(values, p)
+}
+
+func (c *cmdSweep) sweep(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	levels, err := flags.GetIntSlice("workers")
+	if err != nil {
+		return err
+	}
+	levels, err = parseSweepLevels(levels)
+	if err != nil {
+		return err
+	}
+	maxWorkers := levels[len(levels)-1]
+
+	pgconn, err := postgres.GetConfig(flags)
+	if err != nil {
+		return fmt.Errorf("failed to parse postgres config cli flags: %w", err)
+	}
+	pgconn.Exec, err = postgres.GetExecConfig(flags)
+	if err != nil {
+		return fmt.Errorf("failed to parse exec config cli flags: %w", err)
+	}
+	pgconn.Pool, err = postgres.GetPoolConfig(flags, maxWorkers)
+	if err != nil {
+		return fmt.Errorf("failed to parse pool config cli flags: %w", err)
+	}
+	connMode, err := getConnMode(flags)
+	if err != nil {
+		return err
+	}
+	fmtProcess, err := domain.GetCsvConfig(flags)
+	if err != nil {
+		return fmt.Errorf("failed to parse csv config cli flags: %w", err)
+	}
+	fmtProcess.Source = args[0]
+	if fmtProcess.Source == "-" {
+		fmtProcess.Source = "stdin"
+	}
+	c.gs.logger.WithField("timestamps", fmtProcess.Timestamp).Info("timestamp interpretation")
+
+	summaryFormat, err := flags.GetString("summary-format")
+	if err != nil {
+		return err
+	}
+	if err := validateSummaryFormat(summaryFormat); err != nil {
+		return err
+	}
+	output := outputOptions{
+		format: summaryFormat, maxWidth: c.gs.stdOut.width(), color: c.gs.stdOut.isTTY && !c.gs.flags.noColor,
+	}
+	out, err := flags.GetString("out")
+	if err != nil {
+		return err
+	}
+
+	// the same request set is executed at every level, so the input is read once up front
+	var requests []domain.Request
+	errCh := make(chan error, 1)
+	fmtProcess.Run(csv.WithIoReader(stdinOrFile(args[0], c.gs.stdIn)), domain.TaskHandlerFunc(
+		func(r domain.Request, _ int) error {
+			requests = append(requests, r)
+			return nil
+		}), c.gs.logger, errCh)
+	if err := <-errCh; err != nil {
+		c.gs.logger.WithError(err).Error("csv processing failed")
+		return err
+	}
+	if len(requests) == 0 {
+		return fmt.Errorf("no requests to execute in %s", fmtProcess.Source)
+	}
+
+	meta := runMetadata{
+		Version:  consts.FullVersion(),
+		Target:   pgconn.Target(),
+		ConnMode: connMode,
+		ExecMode: pgconn.Exec.Mode,
+		Prepared: pgconn.Exec.Prepare,
+		MaxConns: pgconn.Pool.MaxConns,
+		MinConns: pgconn.Pool.MinConns,
+		Started:  time.Now(),
+	}
+	c.gs.logger.WithFields(meta.fields()).WithField("levels", levels).Info("sweep metadata")
+
+	// the connections of all levels are established up front and kept for the whole sweep
+	handlers, err := openHandlers(c.gs.ctx, pgconn, connMode, maxWorkers)
+	if err != nil {
+		c.gs.logger.WithError(err).Error("Unable to connect to database")
+		return err
+	}
+	defer handlers.close()
+
+	results := make([]sweepLevel, 0, len(levels))
+	for _, workers := range levels {
+		level, err := executeRequests(c.gs.ctx, requests, workers, handlers.handler, c.gs.logger)
+		if err != nil {
+			return err
+		}
+		c.gs.logger.WithFields(logrus.Fields{
+			"workers": workers,
+			"elapsed": level.elapsed,
+			"qps":     fmt.Sprintf("%.2f", level.qps()),
+			"p95":     formatLatency(level.percentile(95)),
+			"errors":  level.errors(),
+		}).Info("sweep level finished")
+		results = append(results, level)
+	}
+
+	knee := sweepKnee(results)
+	t := sweepTable(results, knee)
+	sections := []resultSection{{
+		title: fmt.Sprintf("SCALABILITY BY WORKERS (%d REQUESTS PER LEVEL)", len(requests)), table: t, summary: true,
+	}}
+	if err := renderResults(meta, sections, output, c.gs.stdOut); err != nil {
+		return err
+	}
+
+	if out != "" {
+		if err := writeSweep(out, results, knee); err != nil {
+			return err
+		}
+		c.gs.logger.WithField("file", out).Info("sweep results written")
+	}
+	return nil
+}
+
+// parseSweepLevels sorts the concurrency levels, every level needs at least one worker and may be given only once.
+func parseSweepLevels(levels []int) ([]int, error) {
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("sweep needs at least one number of workers")
+	}
+	sorted := append([]int(nil), levels...)
+	sort.Ints(sorted)
+	for i, n := range sorted {
+		if n < 1 {
+			return nil, fmt.Errorf("number of workers must be at least 1, got %d", n)
+		}
+		if i > 0 && sorted[i-1] == n {
+			return nil, fmt.Errorf("number of workers %d is given more than once", n)
+		}
+	}
+	return sorted, nil
+}
+
+// executeRequests runs the requests on a dispatcher with the given number of workers and waits until all of them
+// are finished.
+func executeRequests(
+	ctx context.Context, requests []domain.Request, workers int, handlerFor func(id int) domain.Handler,
+	logger *logrus.Logger,
+) (sweepLevel, error) {
+	level := sweepLevel{workers: workers, requests: len(requests)}
+	sampleCh := make(chan statistics.Sample, 10)
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for sample := range sampleCh {
+			level.samples = append(level.samples, sample)
+		}
+	}()
+
+	qd := queue.NewDispatcher(workers, queue.Options{})
+	go qd.Run(ctx)
+	jq := &domain.QueueHandler{
+		QueueJobHandler: domain.QueueJobHandlerFunc(func(job *domain.QueueJob) error {
+			return qd.Queue(job)
+		}),
+		TaskHandler: domain.TaskHandlerFunc(func(r domain.Request, id int) error {
+			_, err := LogDurationHandler(handlerFor(id), id, logger, sampleCh).Process(r)
+			return err
+		}),
+	}
+
+	start := time.Now()
+	var err error
+	for _, r := range requests {
+		if err = jq.Process(r, 0); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		if err = qd.Drain(ctx); err != nil {
+			err = fmt.Errorf("failed to finish queued requests: %w", err)
+		}
+	}
+	level.elapsed = time.Since(start)
+	qd.Stop()
+
+	close(sampleCh)
+	<-collected
+	return level, err
+}
+
+// sweepKnee returns the index of the level after which adding workers stops paying off in throughput, -1 if there
+// is none.
+func sweepKnee(levels []sweepLevel) int {
+	x := make([]float64, len(levels))
+	y := make([]float64, len(levels))
+	for i, l := range levels {
+		x[i], y[i] = float64(l.workers), l.qps()
+	}
+	return statistics.Knee(x, y, kneeDistance)
+}
+
+// sweepTable lists throughput, latency and errors of every concurrency level.
+func sweepTable(levels []sweepLevel, knee int) table.Table {
+	t := table.NewTable([]table.Column{
+		table.NewColumn("WORKERS").WithType(table.TypeInt),
+		table.NewColumn("REQUESTS").WithType(table.TypeInt),
+		table.NewColumn("ERRORS").WithType(table.TypeInt),
+		table.NewColumn("ERROR_RATE").WithType(table.TypeFloat),
+		table.NewColumn("QPS").WithType(table.TypeFloat),
+		latencyColumn("P50"),
+		latencyColumn("P95"),
+		table.NewColumn("NOTE").WithLeftAlign(),
+	}, []table.Row{})
+
+	for i, l := range levels {
+		note := ""
+		if i == knee {
+			note = "knee"
+		}
+		p50, p95 := l.percentile(50), l.percentile(95)
+		t.Append(table.Row{
+			strconv.Itoa(l.workers),
+			strconv.Itoa(l.requests),
+			strconv.Itoa(l.errors()),
+			fmt.Sprintf("%.2f%%", 100*l.errorRate()),
+			fmt.Sprintf("%.2f", l.qps()),
+			formatLatency(p50),
+			formatLatency(p95),
+			note,
+		}, []table.Value{
+			l.workers, l.requests, l.errors(), 100 * l.errorRate(), l.qps(), millis(p50), millis(p95), note,
+		})
+	}
+	return t
+}
+
+// writeSweep exports the sweep as csv file with a row per concurrency level, ready to be charted.
+func writeSweep(path string, levels []sweepLevel, knee int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cmd.writeSweep: failed to create sweep file %w", err)
+	}
+	defer f.Close()
+
+	w := stdcsv.NewWriter(f)
+	header := []string{"workers", "requests", "errors", "error_rate", "qps", "p50_ms", "p95_ms", "knee"}
+	if err := w.Write(header); err != nil {
+		return fmt.Errorf("cmd.writeSweep: failed to write header %w", err)
+	}
+	for i, l := range levels {
+		err := w.Write([]string{
+			strconv.Itoa(l.workers),
+			strconv.Itoa(l.requests),
+			strconv.Itoa(l.errors()),
+			strconv.FormatFloat(l.errorRate(), 'f', 4, 64),
+			strconv.FormatFloat(l.qps(), 'f', 2, 64),
+			strconv.FormatFloat(l.percentile(50), 'f', 4, 64),
+			strconv.FormatFloat(l.percentile(95), 'f', 4, 64),
+			strconv.FormatBool(i == knee),
+		})
+		if err != nil {
+			return fmt.Errorf("cmd.writeSweep: failed to write level %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("cmd.writeSweep: failed to flush sweep %w", err)
+	}
+	return f.Close()
+}
+
+func (c *cmdSweep) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.IntSliceP("workers", "w", []int{1, 2, 4, 8, 16, 32}, "Comma separated numbers of workers to execute "+
+		"the request set with")
+	flags.String("out", "", "Export throughput, p50, p95 and error rate of every number of workers as csv to the "+
+		"given file")
+	flags.String("summary-format", table.FormatText, "Format of the result table, possible values are "+
+		strings.Join(table.Formats, ","))
+	flags.AddFlagSet(postgresFlagSet())
+	flags.AddFlagSet(execFlagSet())
+	flags.AddFlagSet(csvFlagSet())
+	return flags
+}
+
+func getCmdSweep(gs *globalState) *cobra.Command {
+	c := &cmdSweep{
+		gs: gs,
+	}
+
+	sweepCmd := &cobra.Command{
+		Use:   "sweep",
+		Short: "Benchmark the request set at several numbers of workers",
+		Long: `Benchmark the request set at several numbers of workers.
+
+The whole request set is executed once per number of workers. Throughput, p50 and p95 latency and the error
+rate are reported against the number of workers, and the knee point after which adding workers stops paying
+off in throughput is marked.`,
+		Args: exactArgsWithMsg(1, "arg should either be \"-\", if reading data from stdin, or a path to a data file"),
+		RunE: c.sweep,
+	}
+	sweepCmd.Flags().SortFlags = false
+	sweepCmd.Flags().AddFlagSet(c.flagSet())
+	return sweepCmd
+}
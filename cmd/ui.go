@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"strconv"
 	"sync"
 )
 
@@ -38,3 +39,15 @@ func (w *consoleWriter) Write(p []byte) (n int, err error) {
 	}
 	return origLen, err
 }
+
+// width returns the number of columns of the terminal, or 0 if the output is not a terminal.
+func (w *consoleWriter) width() int {
+	if !w.isTTY {
+		return 0
+	}
+	if n := terminalWidth(w.rawOut); n > 0 {
+		return n
+	}
+	n, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return n
+}
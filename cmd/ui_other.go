@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package cmd
+
+import "os"
+
+// terminalWidth returns the number of columns of the terminal behind the file, or 0 if unknown.
+func terminalWidth(*os.File) int {
+	return 0
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lfordyce/tiger/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// cmdValidate handles the `tiger validate` sub-command
+type cmdValidate struct {
+	gs *globalState
+}
+
+func (c *cmdValidate) validate(cmd *cobra.Command, args []string) error {
+	fmtProcess, err := domain.GetCsvConfig(cmd.Flags())
+	if err != nil {
+		return fmt.Errorf("failed to parse csv config cli flags: %w", err)
+	}
+	c.gs.logger.WithField("timestamps", fmtProcess.Timestamp).Info("timestamp interpretation")
+
+	file := stdinOrFile(args[0], c.gs.stdIn)
+	defer file.Close()
+
+	report, err := fmtProcess.Validate(file)
+	if err != nil {
+		return fmt.Errorf("failed to validate input file: %w", err)
+	}
+
+	for _, p := range report.Problems {
+		printToStdout(c.gs, p.String()+"\n")
+	}
+	printToStdout(c.gs, fmt.Sprintf("checked %d rows: %d valid, %d invalid, %d problems found\n",
+		report.Rows, report.Rows-report.InvalidRows, report.InvalidRows, len(report.Problems)))
+
+	if !report.Valid() {
+		return fmt.Errorf("input file is invalid: %d problems found", len(report.Problems))
+	}
+	return nil
+}
+
+func getCmdValidate(gs *globalState) *cobra.Command {
+	c := &cmdValidate{
+		gs: gs,
+	}
+
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate an input file",
+		Long: `Validate an input file without running the benchmark.
+
+Every row is checked for a hostname, parsable timestamps and a start time before the end time. All
+problems are reported with their line number and the command exits with a non-zero status if any were found.`,
+		Args: exactArgsWithMsg(1, "arg should either be \"-\", if reading data from stdin, or a path to a data file"),
+		RunE: c.validate,
+	}
+	validateCmd.Flags().SortFlags = false
+	validateCmd.Flags().AddFlagSet(csvFlagSet())
+	return validateCmd
+}
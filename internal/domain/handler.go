@@ -3,16 +3,25 @@ package domain
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 )
 
+// Result is the outcome of executing a single Request.
+type Result struct {
+	// Elapsed is the execution time reported by the database in milliseconds.
+	Elapsed float64
+	// BackendPID is the process id of the database backend that executed the request.
+	BackendPID uint32
+}
+
 type Handler interface {
-	Process(Request) (float64, error)
+	Process(Request) (Result, error)
 }
 
-type HandlerFunc func(Request) (float64, error)
+type HandlerFunc func(Request) (Result, error)
 
-func (hf HandlerFunc) Process(r Request) (float64, error) {
+func (hf HandlerFunc) Process(r Request) (Result, error) {
 	return hf(r)
 }
 
@@ -27,28 +36,34 @@ func (shf TaskHandlerFunc) Process(r Request, n int) error {
 	return shf(r, n)
 }
 
+// QueueJobHandler submits a job to a queue, it returns an error if the queue does not accept jobs anymore.
 type QueueJobHandler interface {
-	Handle(*QueueJob)
+	Handle(*QueueJob) error
 }
 
-type QueueJobHandlerFunc func(*QueueJob)
+type QueueJobHandlerFunc func(*QueueJob) error
 
-func (qjhf QueueJobHandlerFunc) Handle(qj *QueueJob) {
-	qjhf(qj)
+func (qjhf QueueJobHandlerFunc) Handle(qj *QueueJob) error {
+	return qjhf(qj)
 }
 
 type QueueHandler struct {
 	QueueJobHandler
 	TaskHandler
+	// Schedule paces the requests and sets their intended start, without a schedule requests are queued as fast as
+	// the queue accepts them and have no intended start.
+	Schedule *Schedule
 }
 
 func (qh *QueueHandler) Process(r Request, _ int) error {
+	if qh.Schedule != nil {
+		r.Intended = qh.Schedule.Wait()
+	}
 	qj := &QueueJob{
 		r:  r,
 		th: qh.TaskHandler,
 	}
-	qh.QueueJobHandler.Handle(qj)
-	return nil
+	return qh.QueueJobHandler.Handle(qj)
 }
 
 type QueueJob struct {
@@ -75,7 +90,11 @@ func (qj *QueueJob) ShouldRetry(err error) bool {
 }
 
 func (qj *QueueJob) Fail(err error) {
-	log.Printf("failed to execute job %v\n", err)
+	if origin := qj.r.Origin(); origin != "" {
+		log.Printf("failed to execute job from %s: %v\n", origin, err)
+		return
+	}
+	log.Printf("failed to execute job: %v\n", err)
 }
 
 type QueueError struct {
@@ -86,6 +105,9 @@ type QueueError struct {
 }
 
 func (qe *QueueError) Error() string {
+	if origin := qe.request.Origin(); origin != "" {
+		return fmt.Sprintf("%s: %v", origin, qe.err)
+	}
 	return qe.err.Error()
 }
 
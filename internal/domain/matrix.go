@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MatrixParam is a query parameter together with every value it is benchmarked with.
+type MatrixParam struct {
+	Key    string
+	Values []string
+}
+
+// Matrix describes variants of the benchmark query. Every request is executed once for each combination of
+// parameter values, called a cell of the matrix. The cells are computed once, requests of the same cell share
+// their Params.
+type Matrix struct {
+	Params []MatrixParam
+	cells  []map[string]string
+}
+
+// NewMatrix creates a matrix of the parameters.
+func NewMatrix(params ...MatrixParam) Matrix {
+	return Matrix{Params: params, cells: cells(params)}
+}
+
+// ParseMatrix parses parameters in the form key=value1,value2,... Repeating a key is an error.
+func ParseMatrix(specs []string) (Matrix, error) {
+	params := make([]MatrixParam, 0, len(specs))
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return Matrix{}, fmt.Errorf("matrix parameter should be in the form `key=value1,value2` but is `%s`", spec)
+		}
+		key := strings.TrimSpace(parts[0])
+		if seen[key] {
+			return Matrix{}, fmt.Errorf("duplicate matrix parameter '%s'", key)
+		}
+		seen[key] = true
+
+		var values []string
+		for _, v := range strings.Split(parts[1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return Matrix{}, fmt.Errorf("matrix parameter '%s' has no values", key)
+		}
+		params = append(params, MatrixParam{Key: key, Values: values})
+	}
+	return NewMatrix(params...), nil
+}
+
+// Empty reports whether the matrix has no parameters, requests are then executed once with the plain query.
+func (m Matrix) Empty() bool {
+	return len(m.Params) == 0
+}
+
+// Cells returns every combination of parameter values. An empty matrix has no cells.
+func (m Matrix) Cells() []map[string]string {
+	return m.cells
+}
+
+// cells computes the cartesian product of the parameter values.
+func cells(params []MatrixParam) []map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+	cells := []map[string]string{{}}
+	for _, p := range params {
+		next := make([]map[string]string, 0, len(cells)*len(p.Values))
+		for _, cell := range cells {
+			for _, v := range p.Values {
+				c := make(map[string]string, len(cell)+1)
+				for k, cv := range cell {
+					c[k] = cv
+				}
+				c[p.Key] = v
+				next = append(next, c)
+			}
+		}
+		cells = next
+	}
+	return cells
+}
+
+// Expand returns one copy of the request for every cell of the matrix, or just the request if the matrix is empty.
+func (m Matrix) Expand(r Request) []Request {
+	if len(m.cells) == 0 {
+		return []Request{r}
+	}
+	requests := make([]Request, 0, len(m.cells))
+	for _, cell := range m.cells {
+		r.Params = cell
+		requests = append(requests, r)
+	}
+	return requests
+}
+
+// Cell describes the matrix cell of the request, e.g. "agg=max, bucket=5 minutes".
+func (r Request) Cell() string {
+	keys := make([]string, 0, len(r.Params))
+	for k := range r.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+r.Params[k])
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatrix(t *testing.T) {
+	m, err := ParseMatrix([]string{"bucket=1 minute, 5 minutes,1 hour", "agg=max,avg"})
+	require.NoError(t, err)
+	assert.Equal(t, []MatrixParam{
+		{Key: "bucket", Values: []string{"1 minute", "5 minutes", "1 hour"}},
+		{Key: "agg", Values: []string{"max", "avg"}},
+	}, m.Params)
+	assert.Len(t, m.Cells(), 6)
+
+	for _, spec := range []string{"bucket", "bucket=", "=1 minute", "bucket=,"} {
+		_, err := ParseMatrix([]string{spec})
+		assert.Error(t, err, spec)
+	}
+
+	_, err = ParseMatrix([]string{"agg=max", "agg=min"})
+	assert.Error(t, err)
+}
+
+func TestMatrixExpand(t *testing.T) {
+	r := Request{HostID: "host_000001"}
+
+	assert.Equal(t, []Request{r}, Matrix{}.Expand(r))
+	assert.True(t, NewMatrix().Empty())
+
+	m := NewMatrix(
+		MatrixParam{Key: "bucket", Values: []string{"1 minute", "1 hour"}},
+		MatrixParam{Key: "agg", Values: []string{"max", "avg", "min"}},
+	)
+	expanded := m.Expand(r)
+	require.Len(t, expanded, 6)
+
+	var cells []string
+	for _, e := range expanded {
+		assert.Equal(t, r.HostID, e.HostID)
+		cells = append(cells, e.Cell())
+	}
+	assert.Equal(t, []string{
+		"agg=max, bucket=1 minute",
+		"agg=avg, bucket=1 minute",
+		"agg=min, bucket=1 minute",
+		"agg=max, bucket=1 hour",
+		"agg=avg, bucket=1 hour",
+		"agg=min, bucket=1 hour",
+	}, cells)
+}
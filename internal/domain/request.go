@@ -16,6 +16,30 @@ type Request struct {
 	HostID    string
 	StartTime time.Time
 	EndTime   time.Time
+	// Line and Offset locate the row the request was read from in the input file.
+	Line   int
+	Offset int64
+	// Target is the name of the database the request is executed against.
+	Target string
+	// Params holds the values of the matrix cell the request is executed for.
+	Params map[string]string
+	// Source names the input file the request was read from.
+	Source string
+	// Intended is the time the request should have started at according to the Schedule it was paced by, it is
+	// zero for requests that weren't paced. Requests queued behind a slow query start later than intended.
+	Intended time.Time
+}
+
+// Origin describes where in the input file the request was read from, it is empty for requests that were not read
+// from a file.
+func (r Request) Origin() string {
+	if r.Line == 0 {
+		return ""
+	}
+	if r.Offset < 0 {
+		return fmt.Sprintf("line %d", r.Line)
+	}
+	return fmt.Sprintf("line %d (byte %d)", r.Line, r.Offset)
 }
 
 func GetCsvConfig(flags *pflag.FlagSet) (*QueryFormatProcess, error) {
@@ -34,16 +58,16 @@ func GetCsvConfig(flags *pflag.FlagSet) (*QueryFormatProcess, error) {
 		return nil, fmt.Errorf("failed to parse csv-end-hdr flag: %w", err)
 	}
 
-	format, err := flags.GetString("csv-ts-fmt")
+	timestamp, err := GetTimestampConfig(flags)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse csv-ts-fmt flag: %w", err)
+		return nil, err
 	}
 
 	return &QueryFormatProcess{
 		Hostname:  hostHeader,
 		StartTime: startHeader,
 		EndTime:   endHeader,
-		Format:    format,
+		Timestamp: timestamp,
 	}, nil
 }
 
@@ -51,7 +75,13 @@ type QueryFormatProcess struct {
 	Hostname  string
 	StartTime string
 	EndTime   string
-	Format    string // the format of the timestamp column (for format see documentation of go time.Parse())
+	Timestamp *TimestampParser // parses the timestamp columns (for layouts see documentation of go time.Parse())
+	Source    string           // the name of the input file, recorded on every request
+}
+
+// parseTime parses a timestamp column value using the configured layouts.
+func (q *QueryFormatProcess) parseTime(value string) (time.Time, error) {
+	return q.Timestamp.Parse(value)
 }
 
 func (q *QueryFormatProcess) Run(reader csv.Reader, handler TaskHandler, logger *logrus.Logger, errCh chan<- error) {
@@ -59,21 +89,22 @@ func (q *QueryFormatProcess) Run(reader csv.Reader, handler TaskHandler, logger
 		defer reader.Close()
 
 		for data := range reader.C() {
+			entry := logger.WithFields(logrus.Fields{"line": data.Line(), "offset": data.Offset()})
 
-			start, err := time.Parse(q.Format, data.Get(q.StartTime))
+			start, err := q.parseTime(data.Get(q.StartTime))
 			if err != nil {
-				logger.WithError(fmt.Errorf("failed to parse start time: %w", err)).Error()
+				entry.WithError(fmt.Errorf("failed to parse start time: %w", err)).Error()
 				continue
 			}
-			end, err := time.Parse(q.Format, data.Get(q.EndTime))
+			end, err := q.parseTime(data.Get(q.EndTime))
 			if err != nil {
-				logger.WithError(fmt.Errorf("failed to parse end time: %w", err)).Error()
+				entry.WithError(fmt.Errorf("failed to parse end time: %w", err)).Error()
 				continue
 			}
 
 			hostId := data.Get(q.Hostname)
 			if len(hostId) == 0 {
-				logger.WithError(errors.New("invalid hostname: empty value or unexpected header field")).
+				entry.WithError(errors.New("invalid hostname: empty value or unexpected header field")).
 					Error()
 				continue
 			}
@@ -82,6 +113,9 @@ func (q *QueryFormatProcess) Run(reader csv.Reader, handler TaskHandler, logger
 				HostID:    hostId,
 				StartTime: start,
 				EndTime:   end,
+				Line:      data.Line(),
+				Offset:    data.Offset(),
+				Source:    q.Source,
 			}
 			if err := handler.Process(r, 0); err != nil {
 				return fmt.Errorf("failed to process task handler request: %w", err)
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"errors"
+
 	"github.com/lfordyce/tiger/pkg/csv"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
@@ -40,7 +42,7 @@ host_000008,,2017-01-02 19:50:28
 		Hostname:  "hostname",
 		StartTime: "start_time",
 		EndTime:   "start_time",
-		Format:    "2006-01-02 15:04:05",
+		Timestamp: &TimestampParser{Layouts: []string{"2006-01-02 15:04:05"}},
 	}
 	errCh := make(chan error, 1)
 	var collect []Request
@@ -54,3 +56,13 @@ host_000008,,2017-01-02 19:50:28
 	}
 	assert.Equal(t, len(collect), 1)
 }
+
+func TestRequest_Origin(t *testing.T) {
+	assert.Equal(t, "line 3 (byte 42)", Request{Line: 3, Offset: 42}.Origin())
+	assert.Equal(t, "line 3", Request{Line: 3, Offset: -1}.Origin())
+	assert.Equal(t, "", Request{}.Origin())
+
+	err := errors.New("timeout")
+	assert.Equal(t, "line 3 (byte 42): timeout", (&QueueError{request: Request{Line: 3, Offset: 42}, err: err}).Error())
+	assert.Equal(t, "timeout", (&QueueError{request: Request{}, err: err}).Error())
+}
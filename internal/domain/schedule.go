@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Schedule paces requests at a fixed rate and tells when every request was due, which is its intended start. A
+// request is due one interval after the previous one no matter when the previous one was handed over, so time lost
+// waiting for a slow query or a full queue is not omitted from the schedule (coordinated omission): the requests
+// behind it are handed over late and their delay is measured against the time they were due.
+type Schedule struct {
+	interval time.Duration
+	// now and sleep are the clock of the schedule
+	now   func() time.Time
+	sleep func(time.Duration)
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewSchedule creates a schedule of rate requests per second. The first request is due when it is handed over.
+func NewSchedule(rate float64) (*Schedule, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("domain.NewSchedule: rate must be positive, got %g", rate)
+	}
+	return &Schedule{interval: time.Duration(float64(time.Second) / rate), now: time.Now, sleep: time.Sleep}, nil
+}
+
+// Wait blocks until the next request is due and returns the time it was due at. Overdue requests return at once,
+// so a producer that fell behind catches up without moving the schedule.
+func (s *Schedule) Wait() time.Time {
+	now := s.now()
+	s.mu.Lock()
+	if s.next.IsZero() {
+		s.next = now
+	}
+	due := s.next
+	s.next = due.Add(s.interval)
+	s.mu.Unlock()
+
+	if wait := due.Sub(now); wait > 0 {
+		s.sleep(wait)
+	}
+	return due
+}
+
+// Reset restarts the schedule, the next request is due when it is handed over.
+func (s *Schedule) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next = time.Time{}
+}
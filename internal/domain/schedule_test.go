@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock that only moves when the schedule sleeps or the test advances it.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slept = append(c.slept, d)
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestSchedule creates a schedule of rate requests per second on a fake clock.
+func newTestSchedule(t *testing.T, rate float64) (*Schedule, *fakeClock) {
+	schedule, err := NewSchedule(rate)
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)}
+	schedule.now, schedule.sleep = clock.Now, clock.Sleep
+	return schedule, clock
+}
+
+func TestScheduleUniform(t *testing.T) {
+	schedule, clock := newTestSchedule(t, 100)
+	start := clock.Now()
+
+	// a producer that hands over every request at once waits for each to be due
+	for i := 0; i < 20; i++ {
+		due := schedule.Wait()
+		assert.Equal(t, start.Add(time.Duration(i)*10*time.Millisecond), due, "request %d", i)
+		assert.Equal(t, due, clock.Now(), "request %d", i)
+	}
+	assert.Len(t, clock.slept, 19)
+	for _, d := range clock.slept {
+		assert.Equal(t, 10*time.Millisecond, d)
+	}
+}
+
+func TestScheduleStall(t *testing.T) {
+	schedule, clock := newTestSchedule(t, 100)
+	start := schedule.Wait()
+
+	// the producer is held back 100ms by a full queue, the requests due meanwhile keep their place in the schedule
+	// and are handed over at once
+	clock.Advance(100 * time.Millisecond)
+	for i := 1; i <= 10; i++ {
+		assert.Equal(t, start.Add(time.Duration(i)*10*time.Millisecond), schedule.Wait(), "request %d", i)
+	}
+	assert.Empty(t, clock.slept, "overdue requests don't wait")
+
+	// once it caught up the producer is paced again
+	assert.Equal(t, start.Add(110*time.Millisecond), schedule.Wait())
+	assert.Equal(t, []time.Duration{10 * time.Millisecond}, clock.slept)
+}
+
+func TestScheduleReset(t *testing.T) {
+	schedule, clock := newTestSchedule(t, 1000)
+	first := schedule.Wait()
+	assert.Equal(t, first.Add(time.Millisecond), schedule.Wait())
+
+	clock.Advance(20 * time.Millisecond)
+	// overdue requests keep their place in the schedule until it is reset
+	assert.Equal(t, first.Add(2*time.Millisecond), schedule.Wait())
+	schedule.Reset()
+	assert.Equal(t, clock.Now(), schedule.Wait())
+
+	_, err := NewSchedule(0)
+	assert.Error(t, err)
+}
+
+func TestQueueHandlerSchedule(t *testing.T) {
+	schedule, clock := newTestSchedule(t, 100)
+	var jobs []*QueueJob
+	qh := &QueueHandler{
+		QueueJobHandler: QueueJobHandlerFunc(func(qj *QueueJob) error {
+			jobs = append(jobs, qj)
+			// a slow queue doesn't delay the time the following requests are due
+			clock.Advance(25 * time.Millisecond)
+			return nil
+		}),
+		Schedule: schedule,
+	}
+	start := clock.Now()
+	for line := 1; line <= 3; line++ {
+		require.NoError(t, qh.Process(Request{Line: line}, 0))
+	}
+	require.Len(t, jobs, 3)
+	for i, qj := range jobs {
+		assert.Equal(t, start.Add(time.Duration(i)*10*time.Millisecond), qj.r.Intended, "line %d", qj.r.Line)
+	}
+
+	// without a schedule requests have no intended start
+	qh.Schedule = nil
+	require.NoError(t, qh.Process(Request{Line: 4}, 0))
+	assert.True(t, jobs[3].r.Intended.IsZero())
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Presets that can be used in place of a go layout when describing the timestamp columns of an input file.
+const (
+	PresetRFC3339 = "rfc3339"
+	PresetISO8601 = "iso8601"
+	PresetUnix    = "unix"   // seconds since the epoch, optionally with a fractional part
+	PresetUnixMs  = "unixms" // milliseconds since the epoch
+)
+
+// maxUnixSeconds is the last second of the year 9999, unix timestamps beyond it in either direction are rejected
+// rather than turned into times no layout can render.
+const maxUnixSeconds = 253402300799
+
+// iso8601Layouts are the commonly used ISO 8601 variants, most specific first.
+var iso8601Layouts = []string{ // nolint:gochecknoglobals
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// TimestampParser parses timestamp values by trying each of its layouts in turn. Values that don't carry a
+// zone offset are interpreted in Location rather than UTC.
+type TimestampParser struct {
+	Layouts  []string
+	Location *time.Location
+}
+
+// NewTimestampParser creates a TimestampParser from a list of go layouts or presets and an IANA time zone name.
+func NewTimestampParser(formats []string, tz string) (*TimestampParser, error) {
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("at least one timestamp format is required")
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: %w", tz, err)
+	}
+
+	var layouts []string
+	for _, f := range formats {
+		switch strings.ToLower(f) {
+		case PresetRFC3339:
+			layouts = append(layouts, time.RFC3339Nano)
+		case PresetISO8601:
+			layouts = append(layouts, iso8601Layouts...)
+		case PresetUnix, PresetUnixMs:
+			layouts = append(layouts, strings.ToLower(f))
+		default:
+			layouts = append(layouts, f)
+		}
+	}
+	return &TimestampParser{Layouts: layouts, Location: loc}, nil
+}
+
+// GetTimestampConfig creates a TimestampParser from the csv-ts-fmt and csv-tz flags.
+func GetTimestampConfig(flags *pflag.FlagSet) (*TimestampParser, error) {
+	formats, err := flags.GetStringArray("csv-ts-fmt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv-ts-fmt flag: %w", err)
+	}
+
+	tz, err := flags.GetString("csv-tz")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv-tz flag: %w", err)
+	}
+
+	timestamp, err := NewTimestampParser(formats, tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp configuration: %w", err)
+	}
+	return timestamp, nil
+}
+
+// Parse returns the time of the first layout that matches the value.
+func (p *TimestampParser) Parse(value string) (time.Time, error) {
+	var firstErr error
+	for _, layout := range p.Layouts {
+		t, err := p.parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if len(p.Layouts) == 1 {
+		return time.Time{}, firstErr
+	}
+	return time.Time{}, fmt.Errorf("%q does not match any of the layouts %q", value, p.Layouts)
+}
+
+func (p *TimestampParser) parse(layout, value string) (time.Time, error) {
+	switch layout {
+	case PresetUnix:
+		secs, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parsing time %q as unix seconds: %w", value, err)
+		}
+		// NaN fails both comparisons, infinities and huge values the second
+		if !(math.Abs(secs) <= maxUnixSeconds) {
+			return time.Time{}, fmt.Errorf("parsing time %q as unix seconds: value out of range", value)
+		}
+		whole := int64(secs)
+		return time.Unix(whole, int64((secs-float64(whole))*1e9)).In(p.location()), nil
+	case PresetUnixMs:
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parsing time %q as unix milliseconds: %w", value, err)
+		}
+		if ms > maxUnixSeconds*1000 || ms < -maxUnixSeconds*1000 {
+			return time.Time{}, fmt.Errorf("parsing time %q as unix milliseconds: value out of range", value)
+		}
+		return time.UnixMilli(ms).In(p.location()), nil
+	default:
+		return time.ParseInLocation(layout, value, p.location())
+	}
+}
+
+// Format renders the time using the first layout, so that the result can be read back by Parse.
+func (p *TimestampParser) Format(t time.Time) string {
+	if len(p.Layouts) == 0 {
+		return t.Format(time.RFC3339)
+	}
+	switch layout := p.Layouts[0]; layout {
+	case PresetUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case PresetUnixMs:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.In(p.location()).Format(layout)
+	}
+}
+
+// String describes how values are interpreted.
+func (p *TimestampParser) String() string {
+	return fmt.Sprintf("layouts %q, values without a zone offset are in %s", p.Layouts, p.location())
+}
+
+func (p *TimestampParser) location() *time.Location {
+	if p.Location == nil {
+		return time.UTC
+	}
+	return p.Location
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampParser_Parse(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	cases := [...]struct {
+		desc    string
+		formats []string
+		tz      string
+		value   string
+		want    time.Time
+		err     bool
+	}{
+		{
+			desc:    "zone-less value defaults to UTC",
+			formats: []string{"2006-01-02 15:04:05"},
+			tz:      "UTC",
+			value:   "2017-01-01 08:59:22",
+			want:    time.Date(2017, 1, 1, 8, 59, 22, 0, time.UTC),
+		},
+		{
+			desc:    "zone-less value is read in the configured zone",
+			formats: []string{"2006-01-02 15:04:05"},
+			tz:      "America/New_York",
+			value:   "2017-01-01 08:59:22",
+			want:    time.Date(2017, 1, 1, 8, 59, 22, 0, newYork),
+		},
+		{
+			desc:    "explicit offset wins over the configured zone",
+			formats: []string{PresetRFC3339},
+			tz:      "America/New_York",
+			value:   "2017-01-01T08:59:22Z",
+			want:    time.Date(2017, 1, 1, 8, 59, 22, 0, time.UTC),
+		},
+		{
+			desc:    "falls back to the next layout",
+			formats: []string{"2006-01-02 15:04:05", PresetISO8601},
+			tz:      "UTC",
+			value:   "2017-01-01T08:59",
+			want:    time.Date(2017, 1, 1, 8, 59, 0, 0, time.UTC),
+		},
+		{
+			desc:    "unix seconds",
+			formats: []string{PresetUnix},
+			tz:      "UTC",
+			value:   "1483261162.5",
+			want:    time.Date(2017, 1, 1, 8, 59, 22, 5e8, time.UTC),
+		},
+		{
+			desc:    "unix milliseconds",
+			formats: []string{"2006-01-02 15:04:05", PresetUnixMs},
+			tz:      "UTC",
+			value:   "1483261162000",
+			want:    time.Date(2017, 1, 1, 8, 59, 22, 0, time.UTC),
+		},
+		{
+			desc:    "unix seconds NaN",
+			formats: []string{PresetUnix},
+			tz:      "UTC",
+			value:   "NaN",
+			err:     true,
+		},
+		{
+			desc:    "unix seconds infinity",
+			formats: []string{PresetUnix},
+			tz:      "UTC",
+			value:   "-Inf",
+			err:     true,
+		},
+		{
+			desc:    "unix seconds out of range",
+			formats: []string{PresetUnix},
+			tz:      "UTC",
+			value:   "1e400",
+			err:     true,
+		},
+		{
+			desc:    "unix seconds beyond year 9999",
+			formats: []string{PresetUnix},
+			tz:      "UTC",
+			value:   "253402300800",
+			err:     true,
+		},
+		{
+			desc:    "unix milliseconds beyond year 9999",
+			formats: []string{PresetUnixMs},
+			tz:      "UTC",
+			value:   "253402300800000",
+			err:     true,
+		},
+		{
+			desc:    "no layout matches",
+			formats: []string{"2006-01-02 15:04:05", PresetUnix},
+			tz:      "UTC",
+			value:   "yesterday",
+			err:     true,
+		},
+	}
+
+	for _, tst := range cases {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			p, err := NewTimestampParser(tst.formats, tst.tz)
+			require.NoError(t, err)
+
+			got, err := p.Parse(tst.value)
+			if tst.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tst.want.Equal(got), "got %s, want %s", got, tst.want)
+
+			// formatted values can be read back, give or take sub-second precision
+			back, err := p.Parse(p.Format(got))
+			require.NoError(t, err)
+			assert.True(t, got.Truncate(time.Second).Equal(back), "got %s, want %s", back, got)
+		})
+	}
+}
+
+func TestNewTimestampParserUnknownZone(t *testing.T) {
+	_, err := NewTimestampParser([]string{PresetRFC3339}, "Mars/Olympus_Mons")
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	stdcsv "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Problem describes a single defect found while validating an input file.
+type Problem struct {
+	Line  int
+	Field string
+	Err   error
+}
+
+func (p Problem) String() string {
+	if p.Field == "" {
+		return fmt.Sprintf("line %d: %v", p.Line, p.Err)
+	}
+	return fmt.Sprintf("line %d: %s: %v", p.Line, p.Field, p.Err)
+}
+
+// ValidationReport summarises the result of validating an input file.
+type ValidationReport struct {
+	Rows        int
+	InvalidRows int
+	Problems    []Problem
+}
+
+// Valid returns true if no problems were found.
+func (vr *ValidationReport) Valid() bool {
+	return len(vr.Problems) == 0
+}
+
+func (vr *ValidationReport) add(line int, field string, err error) {
+	vr.Problems = append(vr.Problems, Problem{Line: line, Field: field, Err: err})
+}
+
+// Validate checks every row of the input against the configured headers and timestamp format. Unlike Run it
+// does not skip bad rows silently: every problem is collected along with the line it was found on. The
+// returned error is only set when the input could not be read at all.
+func (q *QueryFormatProcess) Validate(r io.Reader) (*ValidationReport, error) {
+	report := &ValidationReport{}
+
+	reader := stdcsv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		report.add(1, "", errors.New("missing header: input is empty"))
+		return report, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	headerLine, _ := reader.FieldPos(0)
+
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[h] = i
+	}
+	for _, h := range []string{q.Hostname, q.StartTime, q.EndTime} {
+		if _, ok := index[h]; !ok {
+			report.add(headerLine, h, fmt.Errorf("missing header field, found %v", header))
+		}
+	}
+	if !report.Valid() {
+		// every row would fail the same way, so there is no point in reporting them one by one
+		return report, nil
+	}
+
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		report.Rows++
+
+		var parseErr *stdcsv.ParseError
+		if errors.As(err, &parseErr) {
+			report.InvalidRows++
+			report.add(parseErr.StartLine, "", parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv record: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		before := len(report.Problems)
+		q.validateRecord(report, line, index, header, fields)
+		if len(report.Problems) > before {
+			report.InvalidRows++
+		}
+	}
+	return report, nil
+}
+
+func (q *QueryFormatProcess) validateRecord(
+	report *ValidationReport, line int, index map[string]int, header, fields []string,
+) {
+	if len(fields) != len(header) {
+		report.add(line, "", fmt.Errorf("ragged row: expected %d fields, found %d", len(header), len(fields)))
+	}
+	get := func(key string) string {
+		if i := index[key]; i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	if len(get(q.Hostname)) == 0 {
+		report.add(line, q.Hostname, errors.New("invalid hostname: empty value"))
+	}
+
+	start, startErr := q.parseTime(get(q.StartTime))
+	if startErr != nil {
+		report.add(line, q.StartTime, fmt.Errorf("failed to parse start time: %w", startErr))
+	}
+	end, endErr := q.parseTime(get(q.EndTime))
+	if endErr != nil {
+		report.add(line, q.EndTime, fmt.Errorf("failed to parse end time: %w", endErr))
+	}
+	if startErr == nil && endErr == nil && !start.Before(end) {
+		report.add(line, q.StartTime, fmt.Errorf("start time %s is not before end time %s",
+			q.Timestamp.Format(start), q.Timestamp.Format(end)))
+	}
+}
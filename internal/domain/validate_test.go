@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFormatProcess_Validate(t *testing.T) {
+	q := &QueryFormatProcess{
+		Hostname:  "hostname",
+		StartTime: "start_time",
+		EndTime:   "end_time",
+		Timestamp: &TimestampParser{Layouts: []string{"2006-01-02 15:04:05"}},
+	}
+
+	cases := [...]struct {
+		desc     string
+		input    string
+		rows     int
+		invalid  int
+		problems []string
+	}{
+		{
+			desc: "valid input",
+			input: `hostname,start_time,end_time
+host_000008,2017-01-01 08:59:22,2017-01-01 09:59:22
+host_000001,2017-01-02 13:02:02,2017-01-02 14:02:02
+`,
+			rows: 2,
+		},
+		{
+			desc: "missing header",
+			input: `host,start_time,end_time
+host_000008,2017-01-01 08:59:22,2017-01-01 09:59:22
+`,
+			problems: []string{"line 1: hostname: missing header field"},
+		},
+		{
+			desc: "every problem is reported with its line",
+			input: `hostname,start_time,end_time
+host_000008,2017-0001-01 08:59:22,2017-01-01 09:59:22
+,2017-01-02 13:02:02,2017-01-02 14:02:02
+host_000008,2017-01-02 19:50:28,2017-01-02 18:50:28
+host_000002,2017-01-02 15:16:29
+host_000003,2017-01-02 15:16:29,2017-01-02 16:16:29
+`,
+			rows:    5,
+			invalid: 4,
+			problems: []string{
+				"line 2: start_time: failed to parse start time",
+				"line 3: hostname: invalid hostname",
+				"line 4: start_time: start time 2017-01-02 19:50:28 is not before end time",
+				"line 5: ragged row: expected 3 fields, found 2",
+				"line 5: end_time: failed to parse end time",
+			},
+		},
+	}
+
+	for _, tst := range cases {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			report, err := q.Validate(strings.NewReader(tst.input))
+			require.NoError(t, err)
+
+			assert.Equal(t, tst.rows, report.Rows)
+			assert.Equal(t, tst.invalid, report.InvalidRows)
+			assert.Equal(t, len(tst.problems) == 0, report.Valid())
+			require.Len(t, report.Problems, len(tst.problems))
+			for i, p := range report.Problems {
+				assert.True(t, strings.HasPrefix(p.String(), tst.problems[i]), "%q does not start with %q",
+					p.String(), tst.problems[i])
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	// embed the time zone database so --csv-tz works in minimal containers
+	_ "time/tzdata"
+
 	"github.com/lfordyce/tiger/cmd"
 )
 
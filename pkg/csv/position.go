@@ -0,0 +1,44 @@
+package csv
+
+import "io"
+
+// lineTracker wraps an io.Reader and remembers the byte offset at which each line starts, so that the line
+// numbers reported by encoding/csv can be translated into byte offsets. encoding/csv buffers its input, so the
+// offset can't simply be counted as records are returned.
+type lineTracker struct {
+	r      io.Reader
+	read   int64
+	first  int     // line number of starts[0]
+	starts []int64 // byte offset of the start of each line that hasn't been discarded yet
+}
+
+func newLineTracker(r io.Reader) *lineTracker {
+	return &lineTracker{
+		r:      r,
+		first:  1,
+		starts: []int64{0},
+	}
+}
+
+func (lt *lineTracker) Read(p []byte) (int, error) {
+	n, err := lt.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			lt.starts = append(lt.starts, lt.read+int64(i)+1)
+		}
+	}
+	lt.read += int64(n)
+	return n, err
+}
+
+// offset returns the byte offset of the start of the given line, or -1 if it is unknown. Records are read in
+// order, so every line before the requested one is discarded to keep memory bounded on large inputs.
+func (lt *lineTracker) offset(line int) int64 {
+	i := line - lt.first
+	if i < 0 || i >= len(lt.starts) {
+		return -1
+	}
+	lt.starts = lt.starts[i:]
+	lt.first = line
+	return lt.starts[0]
+}
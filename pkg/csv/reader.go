@@ -28,21 +28,28 @@ type reader struct {
 
 // WithIoReader creates a csv Reader from the specified io Reader.
 func WithIoReader(io io.ReadCloser) Reader {
-	csvReader := csv.NewReader(io)
+	lines := newLineTracker(io)
+	csvReader := csv.NewReader(lines)
 	csvReader.FieldsPerRecord = -1
-	return WithCsvReader(csvReader, io)
+	return withCsvReader(csvReader, io, lines.offset)
 }
 
 // WithIoReaderAndDelimiter creates a csv Reader from the specified io Reader.
 func WithIoReaderAndDelimiter(io io.ReadCloser, delimiter rune) Reader {
-	csvReader := csv.NewReader(io)
+	lines := newLineTracker(io)
+	csvReader := csv.NewReader(lines)
 	csvReader.Comma = delimiter
 	csvReader.FieldsPerRecord = -1
-	return WithCsvReader(csvReader, io)
+	return withCsvReader(csvReader, io, lines.offset)
 }
 
-// WithCsvReader creates a csv reader from the specified encoding/csv Reader.
+// WithCsvReader creates a csv reader from the specified encoding/csv Reader. The underlying stream is not
+// visible to the reader, so the records it answers only know their line number and not their byte offset.
 func WithCsvReader(r *csv.Reader, c io.Closer) Reader {
+	return withCsvReader(r, c, func(int) int64 { return -1 })
+}
+
+func withCsvReader(r *csv.Reader, c io.Closer, offset func(line int) int64) Reader {
 	ch := make(chan Record)
 	result := &reader{
 		init: make(chan interface{}),
@@ -69,7 +76,7 @@ func WithCsvReader(r *csv.Reader, c io.Closer) Reader {
 			result.header = h
 			close(result.init)
 		}
-		builder := NewRecordBuilder(result.header)
+		builder := newPositionedRecordBuilder(result.header)
 		for {
 			if a, e := r.Read(); e != nil {
 				if e != io.EOF {
@@ -77,12 +84,13 @@ func WithCsvReader(r *csv.Reader, c io.Closer) Reader {
 				}
 				break
 			} else {
+				line, _ := r.FieldPos(0)
 				select {
+				case ch <- builder(a, line, offset(line)):
 				case <-result.quit:
-					break
-				default:
+					// the consumer stopped reading, close the input instead of blocking on the next record
+					return
 				}
-				ch <- builder(a)
 			}
 		}
 	}()
@@ -2,7 +2,9 @@ package csv
 
 import (
 	"github.com/stretchr/testify/assert"
+	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -108,3 +110,56 @@ host_000008;2017-01-02 18:50:28;2017-01-02 19:50:28
 	}
 	assert.Equal(t, len(collect), 3)
 }
+
+func TestCsvReaderRecordPosition(t *testing.T) {
+	t.Parallel()
+	csvInput := "hostname,start_time,end_time\n" +
+		"host_000008,2017-01-01 08:59:22,2017-01-01 09:59:22\n" +
+		"\n" +
+		"\"host\n000001\",2017-01-02 13:02:02,2017-01-02 14:02:02\n" +
+		"host_000008,2017-01-02 18:50:28,2017-01-02 19:50:28\n"
+
+	reader := WithIoReader(io.NopCloser(strings.NewReader(csvInput)))
+	var lines []int
+	var offsets []int64
+	for data := range reader.C() {
+		lines = append(lines, data.Line())
+		offsets = append(offsets, data.Offset())
+	}
+	assert.NoError(t, reader.Error())
+	assert.Equal(t, []int{2, 4, 6}, lines)
+	assert.Equal(t, []int64{29, 82, 136}, offsets)
+
+	for i, offset := range offsets {
+		assert.True(t, strings.HasPrefix(csvInput[offset:], []string{"host_000008", "\"host", "host_000008"}[i]))
+	}
+}
+
+type signalCloser struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (s signalCloser) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestCsvReaderClose(t *testing.T) {
+	t.Parallel()
+	input := signalCloser{
+		Reader: strings.NewReader("hostname\nhost_000001\nhost_000002\nhost_000003\n"),
+		closed: make(chan struct{}),
+	}
+
+	reader := WithIoReader(input)
+	<-reader.C()
+	reader.Close()
+
+	// the reader must release the input without the remaining records being consumed
+	select {
+	case <-input.closed:
+	case <-time.After(time.Second):
+		t.Fatal("reader did not close the input after Close")
+	}
+}
@@ -23,6 +23,10 @@ type Record interface {
 	PutAll(r Record)
 	// SameHeader returns true if the receiver and the specified record have the same header.
 	SameHeader(r Record) bool
+	// Line returns the line of the source stream the record starts on, or 0 if it is unknown.
+	Line() int
+	// Offset returns the byte offset of the start of the record in the source stream, or -1 if it is unknown.
+	Offset() int64
 }
 
 type record struct {
@@ -30,6 +34,8 @@ type record struct {
 	index  map[string]int
 	fields []string
 	cache  map[string]string
+	line   int
+	offset int64
 }
 
 type RecordBuilder func(fields []string) Record
@@ -40,8 +46,17 @@ type RecordBuilder func(fields []string) Record
 // This can be used with raw encoding/csv streams in cases where a CSV stream contains
 // more than one record type.
 func NewRecordBuilder(header []string) RecordBuilder {
-	index := NewIndex(header)
+	build := newPositionedRecordBuilder(header)
 	return func(fields []string) Record {
+		return build(fields, 0, -1)
+	}
+}
+
+// newPositionedRecordBuilder is like NewRecordBuilder, but the returned function also records where in the
+// source stream each record was read from.
+func newPositionedRecordBuilder(header []string) func(fields []string, line int, offset int64) Record {
+	index := NewIndex(header)
+	return func(fields []string, line int, offset int64) Record {
 		if len(header) < len(fields) {
 			if _, err := fmt.Fprintf(os.Stderr, "invariant violated: [%d]fields=%v, [%d]header=%v\n", len(fields), fields, len(header), header); err != nil {
 				panic(err)
@@ -53,6 +68,8 @@ func NewRecordBuilder(header []string) RecordBuilder {
 			header: header,
 			index:  index,
 			fields: tmp,
+			line:   line,
+			offset: offset,
 		}
 	}
 }
@@ -102,6 +119,16 @@ func (r *record) AsMap() map[string]string {
 	return result
 }
 
+// Line returns the line of the source stream the record starts on.
+func (r *record) Line() int {
+	return r.line
+}
+
+// Offset returns the byte offset of the start of the record in the source stream.
+func (r *record) Offset() int64 {
+	return r.offset
+}
+
 // AsSlice return the record values as a slice.
 func (r *record) AsSlice() []string {
 	return r.fields
@@ -0,0 +1,144 @@
+package generate
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Distribution describes how hosts are picked for generated requests.
+type Distribution string
+
+const (
+	// Uniform picks every host with the same probability.
+	Uniform Distribution = "uniform"
+	// Zipf picks a few hosts very often and most hosts rarely.
+	Zipf Distribution = "zipf"
+	// Weighted picks hosts proportionally to the number of rows they have in the dataset.
+	Weighted Distribution = "weighted"
+)
+
+// ParseDistribution validates the name of a distribution.
+func ParseDistribution(s string) (Distribution, error) {
+	switch d := Distribution(s); d {
+	case Uniform, Zipf, Weighted:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown host distribution '%s', possible values are uniform,zipf,weighted", s)
+	}
+}
+
+// Host is a host that requests can be generated for.
+type Host struct {
+	Name string
+	Rows int64
+}
+
+// Config describes the requests to generate.
+type Config struct {
+	Hosts        []Host
+	Start        time.Time
+	End          time.Time
+	Windows      []time.Duration
+	Distribution Distribution
+	// ZipfS is the skew of the zipf distribution, it must be greater than 1.
+	ZipfS float64
+	Seed  int64
+}
+
+// Request is a single generated set of query parameters.
+type Request struct {
+	Host  string
+	Start time.Time
+	End   time.Time
+}
+
+// Generator produces random requests that fall within the dataset.
+type Generator struct {
+	cfg  Config
+	rnd  *rand.Rand
+	pick func() string
+}
+
+// New creates a Generator. The same config, including the seed, always yields the same sequence of requests.
+func New(cfg Config) (*Generator, error) {
+	if len(cfg.Hosts) == 0 {
+		return nil, fmt.Errorf("generate.New: at least one host is required")
+	}
+	if !cfg.Start.Before(cfg.End) {
+		return nil, fmt.Errorf("generate.New: start %s is not before end %s", cfg.Start, cfg.End)
+	}
+	if len(cfg.Windows) == 0 {
+		return nil, fmt.Errorf("generate.New: at least one window length is required")
+	}
+	for _, w := range cfg.Windows {
+		if w <= 0 {
+			return nil, fmt.Errorf("generate.New: window length must be positive, got %s", w)
+		}
+	}
+
+	g := &Generator{
+		cfg: cfg,
+		rnd: rand.New(rand.NewSource(cfg.Seed)), // nolint:gosec
+	}
+	hosts := append([]Host(nil), cfg.Hosts...)
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+
+	switch cfg.Distribution {
+	case Uniform:
+		g.pick = func() string {
+			return hosts[g.rnd.Intn(len(hosts))].Name
+		}
+	case Zipf:
+		if cfg.ZipfS <= 1 {
+			return nil, fmt.Errorf("generate.New: zipf skew must be greater than 1, got %v", cfg.ZipfS)
+		}
+		// shuffle so that the most popular hosts depend on the seed rather than on their names
+		g.rnd.Shuffle(len(hosts), func(i, j int) { hosts[i], hosts[j] = hosts[j], hosts[i] })
+		z := rand.NewZipf(g.rnd, cfg.ZipfS, 1, uint64(len(hosts)-1))
+		g.pick = func() string {
+			return hosts[z.Uint64()].Name
+		}
+	case Weighted:
+		cumulative := make([]int64, len(hosts))
+		var total int64
+		for i, h := range hosts {
+			total += h.Rows
+			cumulative[i] = total
+		}
+		if total <= 0 {
+			return nil, fmt.Errorf("generate.New: weighted distribution requires hosts with rows")
+		}
+		g.pick = func() string {
+			n := g.rnd.Int63n(total)
+			return hosts[sort.Search(len(cumulative), func(i int) bool { return cumulative[i] > n })].Name
+		}
+	default:
+		return nil, fmt.Errorf("generate.New: unknown host distribution '%s'", cfg.Distribution)
+	}
+	return g, nil
+}
+
+// Next returns the next random request. Windows longer than the dataset are shortened to fit it.
+func (g *Generator) Next() Request {
+	span := g.cfg.End.Sub(g.cfg.Start)
+	window := g.cfg.Windows[g.rnd.Intn(len(g.cfg.Windows))]
+	if window > span {
+		window = span
+	}
+
+	start := g.cfg.Start
+	if slack := span - window; slack > 0 {
+		start = start.Add(time.Duration(g.rnd.Int63n(int64(slack) + 1)))
+	}
+	// whole seconds read better, but a dataset starting within a second must not be left
+	if start = start.Truncate(time.Second); start.Before(g.cfg.Start) {
+		start = g.cfg.Start
+	}
+	return Request{
+		Host:  g.pick(),
+		Start: start,
+		End:   start.Add(window),
+	}
+}
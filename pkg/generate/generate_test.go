@@ -0,0 +1,103 @@
+package generate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(d Distribution) Config {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	return Config{
+		Hosts: []Host{
+			{Name: "host_000000", Rows: 9000},
+			{Name: "host_000001", Rows: 900},
+			{Name: "host_000002", Rows: 100},
+		},
+		Start:        start,
+		End:          start.Add(24 * time.Hour),
+		Windows:      []time.Duration{time.Hour, 5 * time.Minute},
+		Distribution: d,
+		ZipfS:        2,
+		Seed:         42,
+	}
+}
+
+func TestGeneratorStaysWithinDataset(t *testing.T) {
+	for _, d := range []Distribution{Uniform, Zipf, Weighted} {
+		d := d
+		t.Run(string(d), func(t *testing.T) {
+			cfg := testConfig(d)
+			g, err := New(cfg)
+			require.NoError(t, err)
+
+			for i := 0; i < 1000; i++ {
+				r := g.Next()
+				assert.False(t, r.Start.Before(cfg.Start), "start %s before dataset", r.Start)
+				assert.False(t, r.End.After(cfg.End), "end %s after dataset", r.End)
+				assert.Contains(t, cfg.Windows, r.End.Sub(r.Start))
+			}
+		})
+	}
+}
+
+func TestGeneratorIsReproducible(t *testing.T) {
+	a, err := New(testConfig(Zipf))
+	require.NoError(t, err)
+	b, err := New(testConfig(Zipf))
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, a.Next(), b.Next())
+	}
+}
+
+func TestGeneratorWeightedFollowsRowCount(t *testing.T) {
+	g, err := New(testConfig(Weighted))
+	require.NoError(t, err)
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		counts[g.Next().Host]++
+	}
+	assert.Greater(t, counts["host_000000"], counts["host_000001"])
+	assert.Greater(t, counts["host_000001"], counts["host_000002"])
+}
+
+func TestGeneratorClampsLongWindows(t *testing.T) {
+	cfg := testConfig(Uniform)
+	cfg.Windows = []time.Duration{48 * time.Hour}
+	g, err := New(cfg)
+	require.NoError(t, err)
+
+	r := g.Next()
+	assert.Equal(t, cfg.Start, r.Start)
+	assert.Equal(t, cfg.End, r.End)
+}
+
+func TestGeneratorSubSecondStart(t *testing.T) {
+	cfg := testConfig(Uniform)
+	// a second of slack, every start lies within the second the dataset starts in or the next one
+	cfg.Start = cfg.Start.Add(700 * time.Millisecond)
+	cfg.Windows = []time.Duration{cfg.End.Sub(cfg.Start) - time.Second}
+	g, err := New(cfg)
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		r := g.Next()
+		assert.False(t, r.Start.Before(cfg.Start), "start %s before dataset", r.Start)
+		assert.False(t, r.End.After(cfg.End), "end %s after dataset", r.End)
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	cfg := testConfig(Zipf)
+	cfg.ZipfS = 1
+	_, err := New(cfg)
+	assert.Error(t, err)
+
+	_, err = ParseDistribution("gaussian")
+	assert.Error(t, err)
+}
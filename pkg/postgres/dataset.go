@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// HostRows is the number of cpu_usage rows recorded for a single host.
+type HostRows struct {
+	Host string
+	Rows int64
+}
+
+// Dataset summarises the contents of the cpu_usage table.
+type Dataset struct {
+	Hosts []HostRows
+	Start time.Time
+	End   time.Time
+}
+
+// Describe inspects the cpu_usage table and returns its hosts and the time range it covers.
+func (r Repository) Describe(ctx context.Context) (*Dataset, error) {
+	var start, end *time.Time
+	if err := r.Conn.QueryRow(ctx, "SELECT min(ts), max(ts) FROM cpu_usage").Scan(&start, &end); err != nil {
+		return nil, fmt.Errorf("postgres.Describe: failed to query cpu_usage time range %w", err)
+	}
+	if start == nil || end == nil {
+		return nil, fmt.Errorf("postgres.Describe: cpu_usage table is empty")
+	}
+	ds := &Dataset{Start: *start, End: *end}
+
+	rows, err := r.Conn.Query(ctx, "SELECT host, count(*) FROM cpu_usage GROUP BY host ORDER BY host")
+	if err != nil {
+		return nil, fmt.Errorf("postgres.Describe: failed to query cpu_usage hosts %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h HostRows
+		if err := rows.Scan(&h.Host, &h.Rows); err != nil {
+			return nil, fmt.Errorf("postgres.Describe: failed to scan cpu_usage host %w", err)
+		}
+		ds.Hosts = append(ds.Hosts, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres.Describe: failed to read cpu_usage hosts %w", err)
+	}
+	return ds, nil
+}
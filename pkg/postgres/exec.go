@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgconn/stmtcache"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// benchQuery is the query executed for every request.
+	benchQuery = "SELECT * FROM bench($1::TEXT, $2::TIMESTAMPTZ, $3::TIMESTAMPTZ)"
+	// benchMatrixQuery is the query executed for requests of a matrix cell.
+	benchMatrixQuery = "SELECT * FROM bench_matrix($1::TEXT, $2::TIMESTAMPTZ, $3::TIMESTAMPTZ, $4::INTERVAL, $5::TEXT)"
+	// benchStatement is the name benchQuery is prepared under when prepared statements are requested.
+	benchStatement = "tiger_bench"
+	// benchMatrixStatement is the name benchMatrixQuery is prepared under.
+	benchMatrixStatement = "tiger_bench_matrix"
+	// statementCacheCapacity is the capacity of the pgx statement cache, the same as the pgx default.
+	statementCacheCapacity = 512
+)
+
+// ExecMode controls which protocol pgx uses to send queries to the server.
+type ExecMode string
+
+const (
+	// ExecModeCacheStatement prepares a named statement for every distinct query and caches it on the
+	// connection. This is the pgx default.
+	ExecModeCacheStatement ExecMode = "cache-statement"
+	// ExecModeDescribe caches the description of every distinct query and executes it with the unnamed statement.
+	ExecModeDescribe ExecMode = "describe"
+	// ExecModePrepare prepares the unnamed statement before every execution without any caching.
+	ExecModePrepare ExecMode = "prepare"
+	// ExecModeSimple uses the simple protocol with client side parameter interpolation.
+	ExecModeSimple ExecMode = "simple"
+)
+
+// ParseExecMode validates the name of an execution mode.
+func ParseExecMode(s string) (ExecMode, error) {
+	switch m := ExecMode(s); m {
+	case ExecModeCacheStatement, ExecModeDescribe, ExecModePrepare, ExecModeSimple:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown exec mode '%s', possible values are prepare,describe,simple,cache-statement", s)
+	}
+}
+
+// ExecConfig controls how benchmark queries are executed.
+type ExecConfig struct {
+	Mode ExecMode
+	// Prepare prepares the benchmark query once on every new connection, the same named statement is then
+	// executed for every request regardless of the execution mode.
+	Prepare bool
+}
+
+// GetExecConfig reads the execution config from the exec-mode and prepare flags.
+func GetExecConfig(flags *pflag.FlagSet) (ExecConfig, error) {
+	name, err := flags.GetString("exec-mode")
+	if err != nil {
+		return ExecConfig{}, err
+	}
+	mode, err := ParseExecMode(name)
+	if err != nil {
+		return ExecConfig{}, err
+	}
+
+	prepare, err := flags.GetBool("prepare")
+	if err != nil {
+		return ExecConfig{}, err
+	}
+	if prepare && mode == ExecModeSimple {
+		return ExecConfig{}, fmt.Errorf("prepared statements can't be combined with the simple protocol")
+	}
+	return ExecConfig{Mode: mode, Prepare: prepare}, nil
+}
+
+// apply configures the connections of the pool for the execution mode.
+func (e ExecConfig) apply(cfg *pgxpool.Config) {
+	e.configure(cfg.ConnConfig)
+	if e.Prepare {
+		cfg.AfterConnect = e.afterConnect
+	}
+}
+
+// configure sets up a single connection for the execution mode.
+func (e ExecConfig) configure(cc *pgx.ConnConfig) {
+	switch e.Mode {
+	case ExecModeCacheStatement, "":
+		cc.BuildStatementCache = func(conn *pgconn.PgConn) stmtcache.Cache {
+			return stmtcache.New(conn, stmtcache.ModePrepare, statementCacheCapacity)
+		}
+	case ExecModeDescribe:
+		cc.BuildStatementCache = func(conn *pgconn.PgConn) stmtcache.Cache {
+			return stmtcache.New(conn, stmtcache.ModeDescribe, statementCacheCapacity)
+		}
+	case ExecModePrepare:
+		cc.BuildStatementCache = nil
+	case ExecModeSimple:
+		cc.BuildStatementCache = nil
+		cc.PreferSimpleProtocol = true
+	}
+}
+
+// afterConnect prepares the bench statements on a newly established connection.
+func (e ExecConfig) afterConnect(ctx context.Context, conn *pgx.Conn) error {
+	if _, err := conn.Prepare(ctx, benchStatement, benchQuery); err != nil {
+		return fmt.Errorf("postgres.AfterConnect: failed to prepare bench statement %w", err)
+	}
+	if _, err := conn.Prepare(ctx, benchMatrixStatement, benchMatrixQuery); err != nil {
+		return fmt.Errorf("postgres.AfterConnect: failed to prepare bench matrix statement %w", err)
+	}
+	return nil
+}
+
+// statement returns the sql, or prepared statement name, to execute for a request.
+func (e ExecConfig) statement(matrix bool) string {
+	switch {
+	case matrix && e.Prepare:
+		return benchMatrixStatement
+	case matrix:
+		return benchMatrixQuery
+	case e.Prepare:
+		return benchStatement
+	default:
+		return benchQuery
+	}
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetExecConfig(t *testing.T) {
+	cases := [...]struct {
+		desc string
+		args []string
+		want ExecConfig
+		err  bool
+	}{
+		{
+			desc: "defaults",
+			want: ExecConfig{Mode: ExecModeCacheStatement},
+		},
+		{
+			desc: "prepared describe",
+			args: []string{"--exec-mode", "describe", "--prepare"},
+			want: ExecConfig{Mode: ExecModeDescribe, Prepare: true},
+		},
+		{
+			desc: "unknown mode",
+			args: []string{"--exec-mode", "telepathy"},
+			err:  true,
+		},
+		{
+			desc: "prepared simple protocol",
+			args: []string{"--exec-mode", "simple", "--prepare"},
+			err:  true,
+		},
+	}
+
+	for _, tst := range cases {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+			flags.String("exec-mode", string(ExecModeCacheStatement), "")
+			flags.Bool("prepare", false, "")
+			require.NoError(t, flags.Parse(tst.args))
+
+			got, err := GetExecConfig(flags)
+			if tst.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tst.want, got)
+		})
+	}
+}
+
+func TestExecConfigApply(t *testing.T) {
+	newConfig := func() *pgxpool.Config {
+		cfg, err := pgxpool.ParseConfig("postgres://postgres@localhost:5432/homework")
+		require.NoError(t, err)
+		return cfg
+	}
+
+	cfg := newConfig()
+	ExecConfig{Mode: ExecModeSimple}.apply(cfg)
+	assert.True(t, cfg.ConnConfig.PreferSimpleProtocol)
+	assert.Nil(t, cfg.ConnConfig.BuildStatementCache)
+	assert.Nil(t, cfg.AfterConnect)
+
+	cfg = newConfig()
+	ExecConfig{Mode: ExecModePrepare}.apply(cfg)
+	assert.False(t, cfg.ConnConfig.PreferSimpleProtocol)
+	assert.Nil(t, cfg.ConnConfig.BuildStatementCache)
+
+	cfg = newConfig()
+	e := ExecConfig{Mode: ExecModeDescribe, Prepare: true}
+	e.apply(cfg)
+	assert.NotNil(t, cfg.ConnConfig.BuildStatementCache)
+	assert.NotNil(t, cfg.AfterConnect)
+	assert.Equal(t, benchStatement, e.statement(false))
+	assert.Equal(t, benchMatrixStatement, e.statement(true))
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// UsageRow is a single row of the cpu_usage table.
+type UsageRow struct {
+	Time  time.Time
+	Host  string
+	Usage float64
+}
+
+// EnsureSchema creates the cpu_usage table and turns it into a hypertable if that hasn't been done yet.
+func (r Repository) EnsureSchema(ctx context.Context) error {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS timescaledb",
+		"CREATE TABLE IF NOT EXISTS cpu_usage (ts TIMESTAMPTZ, host TEXT, usage DOUBLE PRECISION)",
+		"SELECT create_hypertable('cpu_usage', 'ts', if_not_exists => TRUE)",
+	}
+	for _, stmt := range statements {
+		if _, err := r.Conn.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("postgres.EnsureSchema: failed to execute %q %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// CopyUsage inserts the rows into the cpu_usage table using the COPY protocol.
+func (r Repository) CopyUsage(ctx context.Context, rows []UsageRow) (int64, error) {
+	n, err := r.Conn.CopyFrom(ctx, pgx.Identifier{"cpu_usage"}, []string{"ts", "host", "usage"},
+		pgx.CopyFromSlice(len(rows), func(i int) ([]interface{}, error) {
+			return []interface{}{rows[i].Time, rows[i].Host, rows[i].Usage}, nil
+		}))
+	if err != nil {
+		return n, fmt.Errorf("postgres.CopyUsage: failed to copy rows into cpu_usage %w", err)
+	}
+	return n, nil
+}
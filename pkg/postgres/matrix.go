@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// MatrixBucket is the matrix parameter selecting the time_bucket width of the lookup query.
+	MatrixBucket = "bucket"
+	// MatrixAgg is the matrix parameter selecting the aggregate function of the lookup query.
+	MatrixAgg = "agg"
+
+	// defaultBucket is the time_bucket width used by the bench function.
+	defaultBucket = "1 minute"
+)
+
+// intervalUnits maps the units of the postgres interval input format to their length, months and years count as 30
+// and 365 days. Only the sign of the total is of interest when checking a bucket width.
+var intervalUnits = map[string]time.Duration{
+	"microsecond": time.Microsecond, "microseconds": time.Microsecond, "us": time.Microsecond,
+	"usec": time.Microsecond, "usecs": time.Microsecond,
+	"millisecond": time.Millisecond, "milliseconds": time.Millisecond, "ms": time.Millisecond,
+	"msec": time.Millisecond, "msecs": time.Millisecond,
+	"second": time.Second, "seconds": time.Second, "s": time.Second, "sec": time.Second, "secs": time.Second,
+	"minute": time.Minute, "minutes": time.Minute, "m": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"hour": time.Hour, "hours": time.Hour, "h": time.Hour, "hr": time.Hour, "hrs": time.Hour,
+	"day": 24 * time.Hour, "days": 24 * time.Hour, "d": 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour, "w": 7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour, "months": 30 * 24 * time.Hour, "mon": 30 * 24 * time.Hour,
+	"mons": 30 * 24 * time.Hour,
+	"year": 365 * 24 * time.Hour, "years": 365 * 24 * time.Hour, "y": 365 * 24 * time.Hour,
+	"yr": 365 * 24 * time.Hour, "yrs": 365 * 24 * time.Hour,
+}
+
+// CheckParams verifies that the parameters of a matrix cell can be passed to bench_matrix.
+func CheckParams(params map[string]string) error {
+	for k, v := range params {
+		switch k {
+		case MatrixBucket:
+			if err := checkBucket(v); err != nil {
+				return err
+			}
+		case MatrixAgg:
+			switch strings.ToLower(v) {
+			case "max", "min", "avg", "sum", "count":
+			default:
+				return fmt.Errorf("unsupported aggregate function '%s', possible values are max,min,avg,sum,count", v)
+			}
+		default:
+			return fmt.Errorf("unknown matrix parameter '%s', possible values are %s,%s", k, MatrixBucket, MatrixAgg)
+		}
+	}
+	return nil
+}
+
+// checkBucket verifies that the bucket is a positive interval in the postgres input format, e.g. 5 minutes, 1h30m or
+// 01:30:00, so that an invalid width is reported before the run instead of failing every request of the cell.
+func checkBucket(bucket string) error {
+	invalid := fmt.Errorf("invalid bucket '%s', expected a positive interval, e.g. 5 minutes or 1 hour", bucket)
+
+	var tokens []string
+	for _, field := range strings.Fields(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(bucket)), "@")) {
+		if strings.Contains(field, ":") {
+			tokens = append(tokens, field)
+			continue
+		}
+		// split runs of numbers and units, as in 1h30m
+		for field != "" {
+			split := strings.IndexFunc(field, unicode.IsLetter)
+			if split == 0 {
+				split = strings.IndexFunc(field, func(r rune) bool { return !unicode.IsLetter(r) })
+			}
+			if split < 0 {
+				split = len(field)
+			}
+			tokens = append(tokens, field[:split])
+			field = field[split:]
+		}
+	}
+	if len(tokens) == 0 {
+		return invalid
+	}
+
+	var total time.Duration
+	for i := 0; i < len(tokens); i++ {
+		if strings.Contains(tokens[i], ":") {
+			d, err := parseClock(tokens[i])
+			if err != nil {
+				return invalid
+			}
+			total += d
+			continue
+		}
+		n, err := strconv.ParseFloat(tokens[i], 64)
+		if err != nil || n < 0 {
+			return invalid
+		}
+		// a trailing number without unit counts as seconds
+		unit := "s"
+		if i+1 < len(tokens) {
+			i++
+			unit = tokens[i]
+		}
+		length, ok := intervalUnits[unit]
+		if !ok {
+			return invalid
+		}
+		total += time.Duration(n * float64(length))
+	}
+	if total <= 0 {
+		return invalid
+	}
+	return nil
+}
+
+// parseClock parses the time part of an interval in the form hh:mm[:ss[.fraction]].
+func parseClock(clock string) (time.Duration, error) {
+	parts := strings.Split(clock, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("too many parts")
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second}[:len(parts)] {
+		n, err := strconv.ParseFloat(parts[i], 64)
+		if err != nil || n < 0 || (i < 2 && n != float64(int(n))) {
+			return 0, fmt.Errorf("invalid part '%s'", parts[i])
+		}
+		d += time.Duration(n * float64(unit))
+	}
+	return d, nil
+}
+
+// matrixArgs returns the bench_matrix arguments of a matrix cell. Without an aggregate function the lookup query
+// computes the max and min usage like bench does.
+func matrixArgs(params map[string]string) (string, interface{}) {
+	bucket, ok := params[MatrixBucket]
+	if !ok {
+		bucket = defaultBucket
+	}
+	if agg, ok := params[MatrixAgg]; ok {
+		return bucket, agg
+	}
+	return bucket, nil
+}
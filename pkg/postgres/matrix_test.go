@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckParams(t *testing.T) {
+	cases := [...]struct {
+		desc   string
+		params map[string]string
+		err    bool
+	}{
+		{desc: "default cell", params: map[string]string{}},
+		{desc: "bucket in words", params: map[string]string{MatrixBucket: "5 minutes"}},
+		{desc: "combined units", params: map[string]string{MatrixBucket: "1 hour 30 mins"}},
+		{desc: "abbreviated units", params: map[string]string{MatrixBucket: "1h30m"}},
+		{desc: "clock notation", params: map[string]string{MatrixBucket: "@ 01:30:00"}},
+		{desc: "fractional seconds", params: map[string]string{MatrixBucket: "0.5 seconds"}},
+		{desc: "bucket and aggregate", params: map[string]string{MatrixBucket: "1 day", MatrixAgg: "AVG"}},
+		{desc: "unknown unit", params: map[string]string{MatrixBucket: "5 fortnights"}, err: true},
+		{desc: "not an interval", params: map[string]string{MatrixBucket: "hourly"}, err: true},
+		{desc: "empty bucket", params: map[string]string{MatrixBucket: " "}, err: true},
+		{desc: "zero width", params: map[string]string{MatrixBucket: "0 minutes"}, err: true},
+		{desc: "negative width", params: map[string]string{MatrixBucket: "-5 minutes"}, err: true},
+		{desc: "invalid clock", params: map[string]string{MatrixBucket: "1:xx"}, err: true},
+		{desc: "unsupported aggregate", params: map[string]string{MatrixAgg: "median"}, err: true},
+		{desc: "unknown parameter", params: map[string]string{"limit": "10"}, err: true},
+	}
+
+	for _, tst := range cases {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			err := CheckParams(tst.params)
+			if tst.err {
+				require.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
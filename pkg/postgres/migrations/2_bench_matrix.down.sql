@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS bench_matrix(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTERVAL, TEXT);
\ No newline at end of file
@@ -0,0 +1,51 @@
+CREATE OR REPLACE FUNCTION bench_matrix(hostname_id TEXT, ts_start TIMESTAMPTZ, ts_end TIMESTAMPTZ, bucket INTERVAL,
+                                        agg TEXT)
+    RETURNS TABLE
+            (
+                elapsed DOUBLE PRECISION
+            )
+    LANGUAGE PLPGSQL
+AS
+$$
+DECLARE
+    _timing    TIMESTAMPTZ;
+    _start_ts  TIMESTAMPTZ;
+    _end_ts    TIMESTAMPTZ;
+    _overhead  NUMERIC; -- in ms
+    _delta     DOUBLE PRECISION;
+    _aggregate TEXT;
+BEGIN
+    -- without an aggregate function the lookup query matches bench(): max and min cpu usage
+    IF agg IS NULL THEN
+        _aggregate := 'MAX(usage), MIN(usage)';
+    ELSIF lower(agg) IN ('max', 'min', 'avg', 'sum', 'count') THEN
+        _aggregate := format('%s(usage)', lower(agg));
+    ELSE
+        RAISE EXCEPTION 'unsupported aggregate function %', agg;
+    END IF;
+
+    _timing := clock_timestamp();
+    _start_ts := clock_timestamp();
+    _end_ts := clock_timestamp();
+    -- take minimum duration as conservative estimate
+    _overhead := 1000 * extract(epoch FROM LEAST(_start_ts - _timing
+        , _end_ts - _start_ts));
+
+    _start_ts := clock_timestamp();
+
+    -- lookup query: aggregated cpu usage of the given hostname for every bucket in the range specified.
+    EXECUTE format('SELECT time_bucket($1, ts) as one_bucket, %s
+        FROM cpu_usage
+        WHERE ts BETWEEN $2 AND $3
+          AND host = $4
+        GROUP BY one_bucket', _aggregate)
+        USING bucket, ts_start, ts_end, hostname_id;
+
+    _end_ts := clock_timestamp();
+    _delta = 1000 * (extract(epoch FROM _end_ts - _start_ts)) - _overhead;
+    RAISE NOTICE 'Timing overhead in ms = %', _overhead;
+    RAISE NOTICE 'Execution time in ms = %' , _delta;
+
+    RETURN QUERY SELECT _delta;
+END;
+$$;
@@ -0,0 +1,79 @@
+CREATE OR REPLACE FUNCTION bench_matrix(hostname_id TEXT, ts_start TIMESTAMPTZ, ts_end TIMESTAMPTZ, bucket INTERVAL,
+                                        agg TEXT)
+    RETURNS TABLE
+            (
+                elapsed DOUBLE PRECISION
+            )
+    LANGUAGE PLPGSQL
+AS
+$$
+DECLARE
+    _timing    TIMESTAMPTZ;
+    _start_ts  TIMESTAMPTZ;
+    _end_ts    TIMESTAMPTZ;
+    _overhead  NUMERIC; -- in ms
+    _delta     DOUBLE PRECISION;
+    _aggregate TEXT := lower(agg);
+BEGIN
+    IF _aggregate IS NOT NULL AND _aggregate NOT IN ('max', 'min', 'avg', 'sum', 'count') THEN
+        RAISE EXCEPTION 'unsupported aggregate function %', agg;
+    END IF;
+
+    _timing := clock_timestamp();
+    _start_ts := clock_timestamp();
+    _end_ts := clock_timestamp();
+    -- take minimum duration as conservative estimate
+    _overhead := 1000 * extract(epoch FROM LEAST(_start_ts - _timing
+        , _end_ts - _start_ts));
+
+    _start_ts := clock_timestamp();
+
+    -- lookup query: aggregated cpu usage of the given hostname for every bucket in the range specified. Every
+    -- aggregate has its own static query, so plans are cached per session like the lookup query of bench() instead
+    -- of being planned on every call. Without an aggregate function the query matches bench(): max and min cpu usage.
+    IF _aggregate IS NULL THEN
+        PERFORM time_bucket(bucket, ts) as one_bucket, MAX(usage), MIN(usage)
+        FROM cpu_usage
+        WHERE ts BETWEEN ts_start AND ts_end
+          AND host = hostname_id
+        GROUP BY one_bucket;
+    ELSIF _aggregate = 'max' THEN
+        PERFORM time_bucket(bucket, ts) as one_bucket, MAX(usage)
+        FROM cpu_usage
+        WHERE ts BETWEEN ts_start AND ts_end
+          AND host = hostname_id
+        GROUP BY one_bucket;
+    ELSIF _aggregate = 'min' THEN
+        PERFORM time_bucket(bucket, ts) as one_bucket, MIN(usage)
+        FROM cpu_usage
+        WHERE ts BETWEEN ts_start AND ts_end
+          AND host = hostname_id
+        GROUP BY one_bucket;
+    ELSIF _aggregate = 'avg' THEN
+        PERFORM time_bucket(bucket, ts) as one_bucket, AVG(usage)
+        FROM cpu_usage
+        WHERE ts BETWEEN ts_start AND ts_end
+          AND host = hostname_id
+        GROUP BY one_bucket;
+    ELSIF _aggregate = 'sum' THEN
+        PERFORM time_bucket(bucket, ts) as one_bucket, SUM(usage)
+        FROM cpu_usage
+        WHERE ts BETWEEN ts_start AND ts_end
+          AND host = hostname_id
+        GROUP BY one_bucket;
+    ELSE
+        PERFORM time_bucket(bucket, ts) as one_bucket, COUNT(usage)
+        FROM cpu_usage
+        WHERE ts BETWEEN ts_start AND ts_end
+          AND host = hostname_id
+        GROUP BY one_bucket;
+    END IF;
+
+    _end_ts := clock_timestamp();
+    _delta = 1000 * (extract(epoch FROM _end_ts - _start_ts)) - _overhead;
+    RAISE NOTICE 'Timing overhead in ms = %', _overhead;
+    RAISE NOTICE 'Execution time in ms = %' , _delta;
+
+    RETURN QUERY SELECT _delta;
+END;
+$$;
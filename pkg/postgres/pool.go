@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/spf13/pflag"
+)
+
+// PoolConfig controls the size of the connection pool.
+type PoolConfig struct {
+	MaxConns int32
+	MinConns int32
+}
+
+// GetPoolConfig reads the pool config from the pool-max-conns and pool-min-conns flags. A maximum size of 0 defaults
+// to the number of workers, so that no worker has to wait for a connection, and an unset minimum size defaults to the
+// maximum size. An explicit minimum size of 0 lets the pool start empty.
+func GetPoolConfig(flags *pflag.FlagSet, workers int) (PoolConfig, error) {
+	maxConns, err := flags.GetInt32("pool-max-conns")
+	if err != nil {
+		return PoolConfig{}, err
+	}
+	minConns, err := flags.GetInt32("pool-min-conns")
+	if err != nil {
+		return PoolConfig{}, err
+	}
+
+	if maxConns == 0 {
+		maxConns = int32(workers)
+	}
+	if !flags.Changed("pool-min-conns") {
+		minConns = maxConns
+	}
+	if maxConns < 1 {
+		return PoolConfig{}, fmt.Errorf("pool-max-conns must be at least 1, got %d", maxConns)
+	}
+	if minConns < 0 || minConns > maxConns {
+		return PoolConfig{}, fmt.Errorf("pool-min-conns must be between 0 and pool-max-conns (%d), got %d",
+			maxConns, minConns)
+	}
+	return PoolConfig{MaxConns: maxConns, MinConns: minConns}, nil
+}
+
+// apply sizes the pool. A zero config keeps the pgxpool defaults, otherwise the minimum size is applied as is, 0
+// included.
+func (p PoolConfig) apply(cfg *pgxpool.Config) {
+	if p.MaxConns > 0 {
+		cfg.MaxConns = p.MaxConns
+		cfg.MinConns = p.MinConns
+	}
+}
+
+// warm establishes every connection the pool may hold, so that connecting isn't measured as part of the
+// first queries.
+func warm(ctx context.Context, pool *pgxpool.Pool) error {
+	n := int(pool.Config().MaxConns)
+	conns := make([]*pgxpool.Conn, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conns[i], errs[i] = pool.Acquire(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, c := range conns {
+		if c != nil {
+			c.Release()
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("postgres.warm: failed to establish connection %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPoolConfig(t *testing.T) {
+	cases := [...]struct {
+		desc    string
+		args    []string
+		workers int
+		want    PoolConfig
+		err     bool
+	}{
+		{
+			desc:    "defaults to the number of workers",
+			workers: 8,
+			want:    PoolConfig{MaxConns: 8, MinConns: 8},
+		},
+		{
+			desc:    "explicit sizes",
+			args:    []string{"--pool-max-conns", "4", "--pool-min-conns", "2"},
+			workers: 8,
+			want:    PoolConfig{MaxConns: 4, MinConns: 2},
+		},
+		{
+			desc:    "explicit empty minimum",
+			args:    []string{"--pool-min-conns", "0"},
+			workers: 8,
+			want:    PoolConfig{MaxConns: 8, MinConns: 0},
+		},
+		{
+			desc:    "min larger than max",
+			args:    []string{"--pool-max-conns", "4", "--pool-min-conns", "6"},
+			workers: 8,
+			err:     true,
+		},
+		{
+			desc:    "no workers",
+			workers: 0,
+			err:     true,
+		},
+	}
+
+	for _, tst := range cases {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+			flags.Int32("pool-max-conns", 0, "")
+			flags.Int32("pool-min-conns", 0, "")
+			require.NoError(t, flags.Parse(tst.args))
+
+			got, err := GetPoolConfig(flags, tst.workers)
+			if tst.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tst.want, got)
+		})
+	}
+}
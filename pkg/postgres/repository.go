@@ -18,6 +18,8 @@ import (
 
 type Repository struct {
 	Conn *pgxpool.Pool
+	// exec selects the statements executed by Process.
+	exec ExecConfig
 }
 
 func GetConfig(flags *pflag.FlagSet) (*DBDetails, error) {
@@ -61,9 +63,31 @@ type DBDetails struct {
 	User     string
 	Password string
 	Port     uint16
+	// URL is a connection URL that takes precedence over the fields above when it is set.
+	URL  string
+	Exec ExecConfig
+	Pool PoolConfig
 }
 
-func (d *DBDetails) OpenConnection(ctx context.Context) (domain.Handler, func(), error) {
+// Target describes the database without exposing the password.
+func (d *DBDetails) Target() string {
+	if d.URL != "" {
+		if u, err := url.Parse(d.URL); err == nil {
+			if u.User != nil {
+				u.User = url.User(u.User.Username())
+			}
+			u.RawQuery = ""
+			return u.String()
+		}
+	}
+	return fmt.Sprintf("%s@%s:%d/%s", d.User, d.Host, d.Port, d.DBName)
+}
+
+// DSN returns the connection string of the database.
+func (d *DBDetails) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
 	connDetails := pgconn.Config{
 		Host:           d.Host,
 		Port:           d.Port,
@@ -72,31 +96,76 @@ func (d *DBDetails) OpenConnection(ctx context.Context) (domain.Handler, func(),
 		Password:       d.Password,
 		ConnectTimeout: time.Second * time.Duration(5),
 	}
+	return ConstructURI(connDetails, "disable")
+}
 
-	dsn := ConstructURI(connDetails, "disable")
-
-	pool, err := pgxpool.Connect(ctx, dsn)
+// Connect establishes a connection pool to the database without running the benchmark migrations.
+func (d *DBDetails) Connect(ctx context.Context) (Repository, func(), error) {
+	pool, err := pgxpool.Connect(ctx, d.DSN())
 	if err != nil {
-		return Repository{}, func() {}, fmt.Errorf("postgres.OpenConnection: failed to establish postgres connection %w", err)
+		return Repository{}, func() {}, fmt.Errorf("postgres.Connect: failed to establish postgres connection %w", err)
 	}
+	return Repository{Conn: pool}, pool.Close, nil
+}
+
+func (d *DBDetails) OpenConnection(ctx context.Context) (domain.Handler, func(), error) {
+	dsn := d.DSN()
 
+	// migrate first, connections may prepare the bench statement as soon as they are established
 	if err := MigrationManager(dsn, MigrationUp); err != nil {
 		return nil, nil, fmt.Errorf("postgres.OpenConnection: failed to run db migrations %w", err)
 	}
 
-	return Repository{Conn: pool}, pool.Close, nil
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("postgres.OpenConnection: invalid connection config %w", err)
+	}
+	d.Exec.apply(cfg)
+	d.Pool.apply(cfg)
+
+	pool, err := pgxpool.ConnectConfig(ctx, cfg)
+	if err != nil {
+		return Repository{}, func() {}, fmt.Errorf("postgres.OpenConnection: failed to establish postgres connection %w", err)
+	}
+
+	if err := warm(ctx, pool); err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("postgres.OpenConnection: failed to warm connection pool %w", err)
+	}
+
+	return Repository{Conn: pool, exec: d.Exec}, pool.Close, nil
 }
 
-func (r Repository) Process(req domain.Request) (float64, error) {
-	var elapsed float64
-	err := r.Conn.QueryRow(context.Background(), "SELECT * FROM bench($1::TEXT, $2::TIMESTAMPTZ, $3::TIMESTAMPTZ)", req.HostID, req.StartTime, req.EndTime).Scan(&elapsed)
+func (r Repository) Process(req domain.Request) (domain.Result, error) {
+	ctx := context.Background()
+	conn, err := r.Conn.Acquire(ctx)
+	if err != nil {
+		return domain.Result{Elapsed: math.NaN()}, fmt.Errorf("postgres.Process: failed to acquire connection %w", err)
+	}
+	defer conn.Release()
+
+	return bench(ctx, conn.Conn(), r.exec, req)
+}
+
+// bench executes the bench statement for the request on the given connection. Requests of a matrix cell are
+// executed with bench_matrix instead.
+func bench(ctx context.Context, conn *pgx.Conn, exec ExecConfig, req domain.Request) (domain.Result, error) {
+	res := domain.Result{Elapsed: math.NaN(), BackendPID: conn.PgConn().PID()}
+	var row pgx.Row
+	if len(req.Params) > 0 {
+		bucket, agg := matrixArgs(req.Params)
+		row = conn.QueryRow(ctx, exec.statement(true), req.HostID, req.StartTime, req.EndTime, bucket, agg)
+	} else {
+		row = conn.QueryRow(ctx, exec.statement(false), req.HostID, req.StartTime, req.EndTime)
+	}
+	err := row.Scan(&res.Elapsed)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return math.NaN(), fmt.Errorf("postgres.Process: elapsed data not found")
+		return res, fmt.Errorf("postgres.Process: elapsed data not found")
 	}
 	if err != nil {
-		return math.NaN(), fmt.Errorf("postgres.Process: failed to query events table %w", err)
+		return res, fmt.Errorf("postgres.Process: failed to query events table %w", err)
 	}
-	return elapsed, nil
+	return res, nil
 }
 
 func ConstructURI(connDetails pgconn.Config, sslmode string) string {
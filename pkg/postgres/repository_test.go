@@ -43,13 +43,13 @@ func TestRunMigrations(t *testing.T) {
 		end, err := time.Parse("2006-01-02 15:04:05", "2017-01-02 14:02:02")
 		assert.NoError(t, err)
 
-		elapsed, err := r.Process(domain.Request{
+		res, err := r.Process(domain.Request{
 			HostID:    "host_000001",
 			StartTime: start,
 			EndTime:   end,
 		})
 		assert.NoError(t, err)
-		assert.True(t, elapsed != math.NaN())
+		assert.True(t, res.Elapsed != math.NaN())
 	})
 	r.Conn.Close()
 	require.NoError(t, MigrationManager(dsn, MigrationDown))
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Target is a named database that requests are executed against.
+type Target struct {
+	Name string
+	DB   *DBDetails
+}
+
+// GetTargets reads the repeatable target flag, each value is in the form name=dsn. Targets share the exec and pool
+// settings of base. Without any target flag, the database described by base is the only target.
+func GetTargets(flags *pflag.FlagSet, base *DBDetails) ([]Target, error) {
+	specs, err := flags.GetStringArray("target")
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return []Target{{Name: base.DBName, DB: base}}, nil
+	}
+
+	targets := make([]Target, 0, len(specs))
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		t, err := ParseTarget(spec, base)
+		if err != nil {
+			return nil, err
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("duplicate target name '%s'", t.Name)
+		}
+		seen[t.Name] = true
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// ParseTarget parses a target in the form name=dsn, where dsn is a postgres:// connection URL.
+func ParseTarget(spec string, base *DBDetails) (Target, error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Target{}, fmt.Errorf("target should be in the form `name=dsn` but is `%s`", spec)
+	}
+
+	u, err := url.Parse(parts[1])
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return Target{}, fmt.Errorf("dsn of target '%s' should be a postgres:// URL", parts[0])
+	}
+
+	db := *base
+	db.URL = parts[1]
+	return Target{Name: parts[0], DB: &db}, nil
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/lfordyce/tiger/internal/domain"
+)
+
+// ConnMode controls how workers share database connections.
+type ConnMode string
+
+const (
+	// ConnModePool lets workers share a connection pool, a worker may use a different backend for every request.
+	ConnModePool ConnMode = "pool"
+	// ConnModePerWorker gives every worker a dedicated connection for its whole lifetime.
+	ConnModePerWorker ConnMode = "per-worker"
+)
+
+// ParseConnMode validates the name of a connection mode.
+func ParseConnMode(s string) (ConnMode, error) {
+	switch m := ConnMode(s); m {
+	case ConnModePool, ConnModePerWorker:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown conn mode '%s', possible values are pool,per-worker", s)
+	}
+}
+
+// WorkerConns holds a dedicated connection for every worker. Connections are established on first use and
+// re-established on the next request if they are lost.
+type WorkerConns struct {
+	cfg  *pgx.ConnConfig
+	exec ExecConfig
+
+	mu    sync.Mutex
+	conns map[int]*pgx.Conn
+}
+
+// OpenPerWorker prepares dedicated connections for the given number of workers. All of them are established
+// before returning, so that connecting isn't measured as part of the first queries.
+func (d *DBDetails) OpenPerWorker(ctx context.Context, workers int) (*WorkerConns, func(), error) {
+	dsn := d.DSN()
+	if err := MigrationManager(dsn, MigrationUp); err != nil {
+		return nil, nil, fmt.Errorf("postgres.OpenPerWorker: failed to run db migrations %w", err)
+	}
+
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("postgres.OpenPerWorker: invalid connection config %w", err)
+	}
+	d.Exec.configure(cfg)
+
+	wc := &WorkerConns{
+		cfg:   cfg,
+		exec:  d.Exec,
+		conns: make(map[int]*pgx.Conn, workers),
+	}
+	for id := 0; id < workers; id++ {
+		if _, err := wc.conn(ctx, id); err != nil {
+			wc.Close()
+			return nil, nil, err
+		}
+	}
+	return wc, wc.Close, nil
+}
+
+// Connect establishes the connection of the worker if it has none, so that connecting isn't measured as part of
+// its first request.
+func (wc *WorkerConns) Connect(id int) error {
+	_, err := wc.conn(context.Background(), id)
+	return err
+}
+
+// Worker returns the handler executing requests on the dedicated connection of the worker.
+func (wc *WorkerConns) Worker(id int) domain.Handler {
+	return domain.HandlerFunc(func(req domain.Request) (domain.Result, error) {
+		ctx := context.Background()
+		conn, err := wc.conn(ctx, id)
+		if err != nil {
+			return domain.Result{Elapsed: math.NaN()}, err
+		}
+
+		res, err := bench(ctx, conn, wc.exec, req)
+		if err != nil && conn.IsClosed() {
+			// the connection was lost, forget it so the next request of the worker reconnects
+			wc.Release(id)
+		}
+		return res, err
+	})
+}
+
+// conn returns the connection of the worker, establishing it if there is none.
+func (wc *WorkerConns) conn(ctx context.Context, id int) (*pgx.Conn, error) {
+	wc.mu.Lock()
+	conn, ok := wc.conns[id]
+	wc.mu.Unlock()
+	if ok && !conn.IsClosed() {
+		return conn, nil
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, wc.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("postgres.WorkerConns: failed to connect worker %d %w", id, err)
+	}
+	if wc.exec.Prepare {
+		if err := wc.exec.afterConnect(ctx, conn); err != nil {
+			_ = conn.Close(ctx)
+			return nil, err
+		}
+	}
+
+	wc.mu.Lock()
+	wc.conns[id] = conn
+	wc.mu.Unlock()
+	return conn, nil
+}
+
+// Release closes the connection of the worker, if it has one.
+func (wc *WorkerConns) Release(id int) {
+	wc.mu.Lock()
+	conn, ok := wc.conns[id]
+	delete(wc.conns, id)
+	wc.mu.Unlock()
+	if ok {
+		_ = conn.Close(context.Background())
+	}
+}
+
+// Close closes the connections of all workers.
+func (wc *WorkerConns) Close() {
+	wc.mu.Lock()
+	conns := wc.conns
+	wc.conns = make(map[int]*pgx.Conn)
+	wc.mu.Unlock()
+	for _, conn := range conns {
+		_ = conn.Close(context.Background())
+	}
+}
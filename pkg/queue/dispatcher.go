@@ -1,66 +1,276 @@
 package queue
 
-var _ Dispatcher = &dispatcher{}
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrClosed is returned when a job is queued after Drain or Stop.
+	ErrClosed = errors.New("queue: dispatcher is closed")
+
+	_ Dispatcher = &dispatcher{}
+)
 
 type Dispatcher interface {
-	Run()
-	Queue(Job)
+	Run(ctx context.Context)
+	Queue(Job) error
+	Drain(ctx context.Context) error
 	Stop()
+	Stats() Stats
+	Resize(n int) error
 }
 
 type dispatcher struct {
-	jobQueue   chan Job
-	workers    []*worker
-	workerPool chan chan Job
+	// jobs is the bounded pending queue, workers take jobs in the order they were queued
+	jobs     chan Job
+	policy   Policy
+	onRetire func(id int)
+	onStart  func(id int)
+
+	quit     chan struct{}
+	stopOnce sync.Once
 
-	quit chan bool
+	mu sync.Mutex
+	// closed rejects new jobs once the dispatcher is drained or stopped
+	closed bool
+	// pending counts the queued, in-flight and retried jobs
+	pending int
+	// idle is closed when pending drops to zero while draining
+	idle chan struct{}
+	// workers are the active workers, the worker at index i has the id i
+	workers []*worker
+	// retired are the workers retired by Resize that may still finish a job, by id
+	retired map[int]*worker
+	running bool
+	// active counts the started worker goroutines, Stop waits for them
+	active   sync.WaitGroup
+	maxDepth int
+	dropped  uint64
+	rejected uint64
 }
 
-func NewDispatcher(workers int) *dispatcher {
+func NewDispatcher(workers int, opts Options) *dispatcher {
+	if opts.Capacity <= 0 {
+		opts.Capacity = DefaultCapacity
+	}
+	if opts.Policy == "" {
+		opts.Policy = PolicyBlock
+	}
 	d := &dispatcher{
-		jobQueue:   make(chan Job),
-		workers:    make([]*worker, workers),
-		workerPool: make(chan chan Job, workers),
-		quit:       make(chan bool, 1),
+		jobs:     make(chan Job, opts.Capacity),
+		policy:   opts.Policy,
+		onRetire: opts.OnRetire,
+		onStart:  opts.OnStart,
+		quit:     make(chan struct{}),
+		workers:  make([]*worker, workers),
+		retired:  make(map[int]*worker),
 	}
 
 	for i := 0; i < workers; i++ {
-		d.workers[i] = newWorker(d, i)
+		d.workers[i] = newWorker(d, i, nil)
 	}
 
 	return d
 }
 
-// Run starts the queue and loops to process all incoming requests. When a job
-// is received, a worker will attempt to be dequeued from the worker pool,
-func (d *dispatcher) Run() {
+// Run starts the workers and blocks until the context is cancelled or Stop is called. Jobs queued before Run wait
+// in the pending queue.
+func (d *dispatcher) Run(ctx context.Context) {
+	d.mu.Lock()
+	select {
+	case <-d.quit:
+		d.mu.Unlock()
+		return
+	default:
+	}
+	d.running = true
 	for _, w := range d.workers {
-		go w.run()
+		d.start(w)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		d.Stop()
+	case <-d.quit:
+	}
+}
+
+// Queue a new job of the dispatcher to submit to the worker pool. When the pending queue is full the policy of the
+// dispatcher applies. It returns ErrClosed once the dispatcher is drained or stopped.
+func (d *dispatcher) Queue(j Job) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ErrClosed
 	}
+	d.pending++
+	d.mu.Unlock()
 
-	for {
+	if err := d.submit(j); err != nil {
+		d.done()
+		return err
+	}
+	return nil
+}
+
+// submit appends an already counted job to the pending queue, used by Queue and for retries.
+func (d *dispatcher) submit(j Job) error {
+	switch d.policy {
+	case PolicyReject:
 		select {
-		case j := <-d.jobQueue:
-			// new job has been received by the dispatcher
-			go func(j Job) {
-				// dispatch the job to the worker pool
-				<-d.workerPool <- j
-			}(j)
+		case d.jobs <- j:
 		case <-d.quit:
-			for _, w := range d.workers {
-				w.stop()
+			return ErrClosed
+		default:
+			d.mu.Lock()
+			d.rejected++
+			d.mu.Unlock()
+			return ErrFull
+		}
+	case PolicyDropOldest:
+		for queued := false; !queued; {
+			select {
+			case d.jobs <- j:
+				queued = true
+				continue
+			case <-d.quit:
+				return ErrClosed
+			default:
+			}
+			select {
+			case old := <-d.jobs:
+				d.mu.Lock()
+				d.dropped++
+				d.mu.Unlock()
+				old.Fail(ErrDropped)
+				d.done()
+			default:
+				// a worker took a job in the meantime
 			}
-			return
 		}
+	default:
+		select {
+		case d.jobs <- j:
+		case <-d.quit:
+			return ErrClosed
+		}
+	}
+
+	d.mu.Lock()
+	if depth := len(d.jobs); depth > d.maxDepth {
+		d.maxDepth = depth
 	}
+	d.mu.Unlock()
+	return nil
 }
 
-// Queue a new job of the dispatcher to submit to the worker pool
-func (d *dispatcher) Queue(j Job) {
-	d.jobQueue <- j
+// done marks a pending job as finished, either executed successfully or failed for good.
+func (d *dispatcher) done() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending--
+	if d.pending == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
 }
 
-// Stop signals the works to stop handling new job requests
+// Drain stops accepting new jobs and waits until all queued and in-flight jobs, including their retries, are
+// finished. It returns the context error if the context is done first and ErrClosed if the dispatcher is stopped
+// while draining. The workers keep running until Stop is called or the Run context is cancelled.
+func (d *dispatcher) Drain(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	if d.pending == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-d.quit:
+		return ErrClosed
+	}
+}
+
+// start runs the worker in a new goroutine, the caller holds the lock.
+func (d *dispatcher) start(w *worker) {
+	d.active.Add(1)
+	go func() {
+		defer d.active.Done()
+		w.run()
+	}()
+}
+
+// Stop signals the workers to stop handling jobs and waits until the jobs they are executing are finished, pending
+// jobs are abandoned. Call Drain first to finish them.
 func (d *dispatcher) Stop() {
-	d.quit <- true
+	d.stopOnce.Do(func() {
+		d.mu.Lock()
+		d.closed = true
+		close(d.quit)
+		d.mu.Unlock()
+	})
+	d.active.Wait()
+}
+
+// Resize changes the number of workers while jobs are flowing. Retired workers finish their current job and take no
+// further jobs, pending jobs stay queued for the remaining workers. Worker ids always range from 0 to n-1: the
+// highest ids are retired first and a new worker reusing the id of a retired one only starts once the retired worker
+// has finished, so no two workers run with the same id.
+func (d *dispatcher) Resize(n int) error {
+	if n < 0 {
+		return fmt.Errorf("queue.Resize: invalid number of workers %d", n)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	select {
+	case <-d.quit:
+		return ErrClosed
+	default:
+	}
+
+	for len(d.workers) > n {
+		w := d.workers[len(d.workers)-1]
+		d.workers = d.workers[:len(d.workers)-1]
+		close(w.retire)
+		if d.running {
+			d.retired[w.id] = w
+		}
+	}
+	for id := len(d.workers); id < n; id++ {
+		w := newWorker(d, id, d.retired[id])
+		delete(d.retired, id)
+		d.workers = append(d.workers, w)
+		if d.running {
+			d.start(w)
+		}
+	}
+	return nil
+}
+
+// Stats returns the current depth and the counters of the pending queue.
+func (d *dispatcher) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return Stats{
+		Depth:    len(d.jobs),
+		MaxDepth: d.maxDepth,
+		Capacity: cap(d.jobs),
+		Workers:  len(d.workers),
+		Dropped:  d.dropped,
+		Rejected: d.rejected,
+	}
 }
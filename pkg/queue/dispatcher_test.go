@@ -0,0 +1,212 @@
+package queue
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// idTracker fails the test if two jobs run with the same worker id at the same time, or with an id outside of the
+// allowed range.
+type idTracker struct {
+	t      *testing.T
+	mu     sync.Mutex
+	active map[int]bool
+	max    int
+	peak   int
+	runs   int
+}
+
+func (it *idTracker) job(sleep time.Duration) Job {
+	return NewJob(func(id int) error {
+		it.mu.Lock()
+		if it.active[id] {
+			it.t.Errorf("worker id %d is used by two jobs at the same time", id)
+		}
+		if id < 0 || id >= it.max {
+			it.t.Errorf("worker id %d is out of range [0, %d)", id, it.max)
+		}
+		it.active[id] = true
+		if len(it.active) > it.peak {
+			it.peak = len(it.active)
+		}
+		it.mu.Unlock()
+
+		time.Sleep(sleep)
+
+		it.mu.Lock()
+		delete(it.active, id)
+		it.runs++
+		it.mu.Unlock()
+		return nil
+	}, nil, nil)
+}
+
+func TestResizeGrow(t *testing.T) {
+	it := &idTracker{t: t, active: make(map[int]bool), max: 4}
+	q := NewDispatcher(1, Options{})
+	go q.Run(context.Background())
+	defer q.Stop()
+
+	if err := q.Resize(4); err != nil {
+		t.Fatalf("failed to resize: %v", err)
+	}
+	for i := 0; i < 8; i++ {
+		if err := q.Queue(it.job(50 * time.Millisecond)); err != nil {
+			t.Fatalf("failed to queue job: %v", err)
+		}
+	}
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("failed to drain: %v", err)
+	}
+	if it.peak != 4 || it.runs != 8 {
+		t.Fatalf("expected 8 runs on 4 concurrent workers, got %d runs on %d", it.runs, it.peak)
+	}
+	if workers := q.Stats().Workers; workers != 4 {
+		t.Fatalf("expected 4 workers, got %d", workers)
+	}
+}
+
+func TestResizeShrink(t *testing.T) {
+	var mu sync.Mutex
+	var retired []int
+	it := &idTracker{t: t, active: make(map[int]bool), max: 4}
+	q := NewDispatcher(4, Options{OnRetire: func(id int) {
+		mu.Lock()
+		retired = append(retired, id)
+		mu.Unlock()
+	}})
+	go q.Run(context.Background())
+	defer q.Stop()
+
+	for i := 0; i < 4; i++ {
+		if err := q.Queue(it.job(30 * time.Millisecond)); err != nil {
+			t.Fatalf("failed to queue job: %v", err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := q.Resize(1); err != nil {
+		t.Fatalf("failed to resize: %v", err)
+	}
+	it.mu.Lock()
+	it.peak = 0
+	it.mu.Unlock()
+	for i := 0; i < 4; i++ {
+		if err := q.Queue(it.job(10 * time.Millisecond)); err != nil {
+			t.Fatalf("failed to queue job: %v", err)
+		}
+	}
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("failed to drain: %v", err)
+	}
+
+	if it.runs != 8 {
+		t.Fatalf("expected 8 runs, got %d", it.runs)
+	}
+	// the retired workers finish the jobs they took before the resize, afterwards a single worker is left
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Ints(retired)
+	if len(retired) != 3 || retired[0] != 1 || retired[2] != 3 {
+		t.Fatalf("expected workers 1, 2 and 3 to retire, got %v", retired)
+	}
+}
+
+func TestResizeWhileRunning(t *testing.T) {
+	it := &idTracker{t: t, active: make(map[int]bool), max: 8}
+	q := NewDispatcher(2, Options{Capacity: 16})
+	go q.Run(context.Background())
+	defer q.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, n := range []int{8, 1, 5, 0, 3, 8, 2} {
+			time.Sleep(5 * time.Millisecond)
+			if err := q.Resize(n); err != nil {
+				t.Errorf("failed to resize to %d: %v", n, err)
+			}
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		if err := q.Queue(it.job(time.Millisecond)); err != nil {
+			t.Fatalf("failed to queue job: %v", err)
+		}
+	}
+	<-done
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("failed to drain: %v", err)
+	}
+	if it.runs != 200 {
+		t.Fatalf("expected 200 runs, got %d", it.runs)
+	}
+}
+
+func TestResizeInvalid(t *testing.T) {
+	q := NewDispatcher(1, Options{})
+	if err := q.Resize(-1); err == nil {
+		t.Fatal("expected error for a negative number of workers")
+	}
+	q.Stop()
+	if err := q.Resize(2); err != ErrClosed {
+		t.Fatalf("expected ErrClosed: %v", err)
+	}
+}
+
+func TestStopWaitsForRunningJobs(t *testing.T) {
+	q := NewDispatcher(1, Options{})
+	go q.Run(context.Background())
+
+	started := make(chan struct{})
+	var finished bool
+	if err := q.Queue(NewJob(func(int) error {
+		close(started)
+		time.Sleep(30 * time.Millisecond)
+		finished = true
+		return nil
+	}, nil, nil)); err != nil {
+		t.Fatalf("failed to queue job: %v", err)
+	}
+
+	<-started
+	q.Stop()
+	if !finished {
+		t.Fatal("Stop returned before the running job finished")
+	}
+}
+
+func TestOnStartBeforeFirstJob(t *testing.T) {
+	var mu sync.Mutex
+	started := make(map[int]int)
+	q := NewDispatcher(1, Options{OnStart: func(id int) {
+		// a slow start, e.g. connecting, must not overlap with the first job of the worker
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		started[id]++
+		mu.Unlock()
+	}})
+	go q.Run(context.Background())
+	defer q.Stop()
+
+	if err := q.Resize(4); err != nil {
+		t.Fatalf("failed to resize: %v", err)
+	}
+	for i := 0; i < 16; i++ {
+		if err := q.Queue(NewJob(func(id int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if started[id] != 1 {
+				t.Errorf("worker %d took a job after %d starts", id, started[id])
+			}
+			return nil
+		}, nil, nil)); err != nil {
+			t.Fatalf("failed to queue job: %v", err)
+		}
+	}
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("failed to drain: %v", err)
+	}
+}
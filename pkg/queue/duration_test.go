@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
@@ -62,8 +63,8 @@ func expectDuration(t0 time.Time, durMin, durMax time.Duration, t *testing.T) {
 func runWorkerJobs(workers int, jobs int) time.Time {
 	quit := make(chan bool)
 
-	q := NewDispatcher(workers)
-	go q.Run()
+	q := NewDispatcher(workers, Options{})
+	go q.Run(context.Background())
 
 	t0 := time.Now()
 
@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultCapacity is the number of pending jobs a dispatcher holds if Options.Capacity is not set.
+const DefaultCapacity = 1024
+
+var (
+	// ErrFull is returned by Queue with PolicyReject when the pending queue is full.
+	ErrFull = errors.New("queue: pending queue is full")
+	// ErrDropped is passed to Job.Fail when a pending job is evicted by PolicyDropOldest.
+	ErrDropped = errors.New("queue: job dropped from full pending queue")
+)
+
+// Policy decides what Queue does when the pending queue is full.
+type Policy string
+
+const (
+	// PolicyBlock waits until a worker takes a pending job.
+	PolicyBlock Policy = "block"
+	// PolicyDropOldest evicts the oldest pending job to make room for the new one.
+	PolicyDropOldest Policy = "drop-oldest"
+	// PolicyReject returns ErrFull without queueing the job.
+	PolicyReject Policy = "reject"
+)
+
+// ParsePolicy validates the name of a queue policy.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case PolicyBlock, PolicyDropOldest, PolicyReject:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown queue policy '%s', possible values are block,drop-oldest,reject", s)
+	}
+}
+
+// Options configure the pending queue and the workers of a dispatcher.
+type Options struct {
+	// Capacity is the maximum number of pending jobs, DefaultCapacity if not set.
+	Capacity int
+	// Policy is applied when the pending queue is full, PolicyBlock if not set.
+	Policy Policy
+	// OnRetire is called with the id of a worker retired by Resize, after it finished its last job. It is meant to
+	// release the resources of the worker, e.g. its dedicated connection.
+	OnRetire func(id int)
+	// OnStart is called with the id of a worker before it takes its first job, for the workers of Run as well as
+	// the ones added by Resize. It is meant to prepare the resources of the worker, e.g. its dedicated connection,
+	// so that preparing them isn't part of the first job.
+	OnStart func(id int)
+}
+
+// Stats describe the pending queue of a dispatcher.
+type Stats struct {
+	// Depth is the current number of pending jobs.
+	Depth int
+	// MaxDepth is the highest number of pending jobs so far.
+	MaxDepth int
+	Capacity int
+	// Workers is the current number of workers.
+	Workers int
+	// Dropped counts the jobs evicted by PolicyDropOldest.
+	Dropped uint64
+	// Rejected counts the jobs refused by PolicyReject.
+	Rejected uint64
+}
@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder collects the ids of executed and failed jobs.
+type recorder struct {
+	mu       sync.Mutex
+	executed []int
+	failed   map[int]error
+}
+
+func (r *recorder) job(id int) Job {
+	return NewJob(
+		func(int) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.executed = append(r.executed, id)
+			return nil
+		},
+		nil,
+		func(err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.failed == nil {
+				r.failed = make(map[int]error)
+			}
+			r.failed[id] = err
+		},
+	)
+}
+
+func drain(t *testing.T, q Dispatcher) {
+	go q.Run(context.Background())
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("failed to drain: %v", err)
+	}
+	q.Stop()
+}
+
+func TestQueueOrder(t *testing.T) {
+	q := NewDispatcher(1, Options{Capacity: 10})
+	r := &recorder{}
+	for i := 0; i < 10; i++ {
+		if err := q.Queue(r.job(i)); err != nil {
+			t.Fatalf("failed to queue job %d: %v", i, err)
+		}
+	}
+	if depth := q.Stats().Depth; depth != 10 {
+		t.Fatalf("expected depth 10, got %d", depth)
+	}
+	drain(t, q)
+
+	if want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(r.executed, want) {
+		t.Fatalf("expected jobs in queue order %v, got %v", want, r.executed)
+	}
+}
+
+func TestPolicyReject(t *testing.T) {
+	q := NewDispatcher(1, Options{Capacity: 2, Policy: PolicyReject})
+	r := &recorder{}
+	for i := 0; i < 2; i++ {
+		if err := q.Queue(r.job(i)); err != nil {
+			t.Fatalf("failed to queue job %d: %v", i, err)
+		}
+	}
+	if err := q.Queue(r.job(2)); !errors.Is(err, ErrFull) {
+		t.Fatalf("expected ErrFull: %v", err)
+	}
+	drain(t, q)
+
+	if want := []int{0, 1}; !reflect.DeepEqual(r.executed, want) {
+		t.Fatalf("expected jobs %v, got %v", want, r.executed)
+	}
+	if stats := q.Stats(); stats.Rejected != 1 || stats.MaxDepth != 2 || stats.Capacity != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestPolicyDropOldest(t *testing.T) {
+	q := NewDispatcher(1, Options{Capacity: 2, Policy: PolicyDropOldest})
+	r := &recorder{}
+	for i := 0; i < 3; i++ {
+		if err := q.Queue(r.job(i)); err != nil {
+			t.Fatalf("failed to queue job %d: %v", i, err)
+		}
+	}
+	drain(t, q)
+
+	if want := []int{1, 2}; !reflect.DeepEqual(r.executed, want) {
+		t.Fatalf("expected jobs %v, got %v", want, r.executed)
+	}
+	if err := r.failed[0]; !errors.Is(err, ErrDropped) {
+		t.Fatalf("expected oldest job to fail with ErrDropped: %v", err)
+	}
+	if stats := q.Stats(); stats.Dropped != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestPolicyBlock(t *testing.T) {
+	q := NewDispatcher(1, Options{Capacity: 1})
+	r := &recorder{}
+	if err := q.Queue(r.job(0)); err != nil {
+		t.Fatalf("failed to queue job: %v", err)
+	}
+
+	queued := make(chan error)
+	go func() {
+		queued <- q.Queue(r.job(1))
+	}()
+	select {
+	case err := <-queued:
+		t.Fatalf("expected Queue to block on a full queue, returned %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	go q.Run(context.Background())
+	defer q.Stop()
+	if err := <-queued; err != nil {
+		t.Fatalf("failed to queue job: %v", err)
+	}
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("failed to drain: %v", err)
+	}
+	if want := []int{0, 1}; !reflect.DeepEqual(r.executed, want) {
+		t.Fatalf("expected jobs %v, got %v", want, r.executed)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, name := range []string{"block", "drop-oldest", "reject"} {
+		if p, err := ParsePolicy(name); err != nil || string(p) != name {
+			t.Fatalf("failed to parse %s: %v", name, err)
+		}
+	}
+	if _, err := ParsePolicy("drop-newest"); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+}
@@ -4,57 +4,91 @@ import (
 	"time"
 )
 
+// retryDelay is the time a failed job waits before it is queued again.
+const retryDelay = 200 * time.Millisecond
+
 type worker struct {
-	d        *dispatcher
-	id       int
-	jobQueue chan Job
-	quit     chan bool
+	d  *dispatcher
+	id int
+	// previous is the retired worker with the same id, it has to exit before this worker starts
+	previous *worker
+	retire   chan struct{}
+	exited   chan struct{}
 }
 
-func newWorker(d *dispatcher, id int) *worker {
+func newWorker(d *dispatcher, id int, previous *worker) *worker {
 	return &worker{
 		d:        d,
 		id:       id,
-		jobQueue: make(chan Job),
-		quit:     make(chan bool, 1),
+		previous: previous,
+		retire:   make(chan struct{}),
+		exited:   make(chan struct{}),
 	}
 }
 
-// run starts listening to the job queue for new jobs, blocking until the quit method is called. The dispatcher
-// should queue a job in a new goroutine.
+// run takes jobs from the pending queue of the dispatcher in order, blocking until the worker is retired or the
+// dispatcher is stopped.
 func (w *worker) run() {
+	defer close(w.exited)
+	if w.previous != nil {
+		select {
+		case <-w.previous.exited:
+			w.previous = nil
+		case <-w.d.quit:
+			return
+		}
+	}
+	if w.d.onStart != nil {
+		w.d.onStart(w.id)
+	}
+
 	for {
-		// Register this worker's job channel to the dispatcher's
-		// worker pool. The dispatcher is expected to use a
-		// buffered channel, so this will return immediately.
-		w.d.workerPool <- w.jobQueue
-
-		// When the dispatcher selects this worker's job channel
-		// from the pool, execute the job. If the quit channel is
-		// signaled, the method will return before a job is queued.
+		// a retired worker must not take another job, even if jobs are pending
 		select {
-		case j := <-w.jobQueue:
-			// work request received, execute the job and handle failures appropriately
-			if err := j.Execute(w.id); err != nil {
-				if j.ShouldRetry(err) {
-					go func() {
-						// TODO: make this a configurable parameter
-						time.Sleep(200 * time.Millisecond)
-						w.d.Queue(j)
-					}()
-				} else {
-					j.Fail(err)
-				}
-			}
-		case <-w.quit:
-			close(w.jobQueue)
-			close(w.quit)
+		case <-w.retire:
+			w.retired()
 			return
+		default:
 		}
+
+		select {
+		case j := <-w.d.jobs:
+			w.execute(j)
+		case <-w.retire:
+			w.retired()
+			return
+		case <-w.d.quit:
+			return
+		}
+	}
+}
+
+func (w *worker) retired() {
+	if w.d.onRetire != nil {
+		w.d.onRetire(w.id)
 	}
 }
 
-// stop sends a signal to a running worked causing them to shut down.
-func (w *worker) stop() {
-	w.quit <- true
+// execute runs the job and handles failures appropriately. A retried job stays pending in the dispatcher, so Drain
+// waits for the retry.
+func (w *worker) execute(j Job) {
+	err := j.Execute(w.id)
+	if err != nil && j.ShouldRetry(err) {
+		go func() {
+			select {
+			case <-time.After(retryDelay):
+				if err := w.d.submit(j); err != nil {
+					j.Fail(err)
+					w.d.done()
+				}
+			case <-w.d.quit:
+				w.d.done()
+			}
+		}()
+		return
+	}
+	if err != nil {
+		j.Fail(err)
+	}
+	w.d.done()
 }
@@ -1,21 +1,24 @@
 package queue
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestQuitNoJobs(t *testing.T) {
-	q := NewDispatcher(1)
-	go q.Run()
+	q := NewDispatcher(1, Options{})
+	go q.Run(context.Background())
 
 	q.Stop()
 }
 
 func TestErrorRetry(t *testing.T) {
 
-	q := NewDispatcher(1)
-	go q.Run()
+	q := NewDispatcher(1, Options{})
+	go q.Run(context.Background())
 
 	attempts := 0
 	errRetry := errors.New("recoverable error")
@@ -48,8 +51,8 @@ func TestErrorRetry(t *testing.T) {
 }
 
 func TestNoJobExecuteFunc(t *testing.T) {
-	q := NewDispatcher(1)
-	go q.Run()
+	q := NewDispatcher(1, Options{})
+	go q.Run(context.Background())
 
 	quit := make(chan error)
 
@@ -69,8 +72,8 @@ func TestNoJobExecuteFunc(t *testing.T) {
 }
 
 func TestNoJobRetryFunc(t *testing.T) {
-	q := NewDispatcher(1)
-	go q.Run()
+	q := NewDispatcher(1, Options{})
+	go q.Run(context.Background())
 
 	eee := errors.New("expected error")
 	quit := make(chan error)
@@ -88,3 +91,111 @@ func TestNoJobRetryFunc(t *testing.T) {
 		t.Fatalf("expected 'expected error': %v", err)
 	}
 }
+
+func TestDrainWaitsForRetries(t *testing.T) {
+	q := NewDispatcher(2, Options{})
+	go q.Run(context.Background())
+	defer q.Stop()
+
+	var mu sync.Mutex
+	attempts, failed := 0, 0
+	errRetry := errors.New("recoverable error")
+
+	for i := 0; i < 4; i++ {
+		retried := false
+		err := q.Queue(NewJob(
+			func(id int) error {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+				if !retried {
+					retried = true
+					return errRetry
+				}
+				return nil
+			},
+			func(err error) bool {
+				return errors.Is(err, errRetry)
+			},
+			func(err error) {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			},
+		))
+		if err != nil {
+			t.Fatalf("failed to queue job: %v", err)
+		}
+	}
+
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("failed to drain: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 8 || failed != 0 {
+		t.Fatalf("expected 8 attempts without failures, got %d attempts and %d failures", attempts, failed)
+	}
+}
+
+func TestQueueAfterDrain(t *testing.T) {
+	q := NewDispatcher(1, Options{})
+	go q.Run(context.Background())
+	defer q.Stop()
+
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("failed to drain idle dispatcher: %v", err)
+	}
+	if err := q.Queue(NewJob(func(int) error { return nil }, nil, nil)); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed: %v", err)
+	}
+}
+
+func TestQueueAfterStop(t *testing.T) {
+	q := NewDispatcher(1, Options{})
+	q.Stop()
+	q.Stop()
+
+	if err := q.Queue(NewJob(func(int) error { return nil }, nil, nil)); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed: %v", err)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	q := NewDispatcher(1, Options{})
+	go q.Run(context.Background())
+	defer q.Stop()
+
+	release := make(chan struct{})
+	defer close(release)
+	if err := q.Queue(NewJob(func(int) error { <-release; return nil }, nil, nil)); err != nil {
+		t.Fatalf("failed to queue job: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded: %v", err)
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	q := NewDispatcher(1, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+	if err := q.Queue(NewJob(func(int) error { return nil }, nil, nil)); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// redacted replaces secrets in the listed flag values.
+const redacted = "*****"
+
+// Flags lists the value of every flag with secrets redacted: the password flag, the password of URL values and of
+// name=url values such as targets, and a password query parameter.
+func Flags(flags *pflag.FlagSet) []Field {
+	var fields []Field
+	flags.VisitAll(func(f *pflag.Flag) {
+		value := f.Value.String()
+		switch v := f.Value.(type) {
+		case pflag.SliceValue:
+			items := v.GetSlice()
+			for i := range items {
+				items[i] = redactValue(items[i])
+			}
+			value = "[" + strings.Join(items, ",") + "]"
+		default:
+			if f.Name == "password" && value != "" {
+				value = redacted
+			} else {
+				value = redactValue(value)
+			}
+		}
+		fields = append(fields, Field{Name: f.Name, Value: value})
+	})
+	return fields
+}
+
+// redactValue redacts the password of a URL, either the whole value or the part after the first '='.
+func redactValue(value string) string {
+	if redactedURL, ok := redactURL(value); ok {
+		return redactedURL
+	}
+	if name, rest, ok := strings.Cut(value, "="); ok {
+		if redactedURL, ok := redactURL(rest); ok {
+			return name + "=" + redactedURL
+		}
+	}
+	return value
+}
+
+// redactURL replaces the userinfo password and the password query parameter of an absolute URL. It reports false
+// if the value isn't an absolute URL.
+func redactURL(value string) (string, bool) {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", false
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	if query := u.Query(); query.Has("password") {
+		query.Set("password", redacted)
+		u.RawQuery = query.Encode()
+	}
+	// url escapes the asterisks of the marker, keep it readable
+	return strings.ReplaceAll(u.String(), url.QueryEscape(redacted), redacted), true
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// Field is a named value listed in the report, such as a piece of run metadata or a flag.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Table is a table of the benchmark results.
+type Table struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+}
+
+// Chart is a titled chart rendered as inline SVG.
+type Chart struct {
+	Title string
+	SVG   template.HTML
+}
+
+// Section groups the charts and tables of a part of the results, e.g. one target.
+type Section struct {
+	Title  string
+	Charts []Chart
+	Tables []Table
+}
+
+// Report is a self-contained HTML page of benchmark results. It does not reference any external resource, so it
+// can be shared as a single file and opened offline.
+type Report struct {
+	Title     string
+	Generated time.Time
+	Metadata  []Field
+	Flags     []Field
+	Sections  []Section
+}
+
+// Write renders the report as HTML.
+func (r Report) Write(w io.Writer) error {
+	if err := page.Execute(w, r); err != nil {
+		return fmt.Errorf("report.Write: failed to render report %w", err)
+	}
+	return nil
+}
+
+var page = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1100px;
+	color: #222; padding: 0 1em; }
+h1 { margin-bottom: 0; }
+h2 { border-bottom: 1px solid #ccc; padding-bottom: .2em; margin-top: 2em; }
+.generated { color: #777; margin-top: .2em; }
+table { border-collapse: collapse; margin: .5em 0 1.5em; font-size: 13px; }
+th, td { border: 1px solid #ddd; padding: 3px 8px; text-align: right; white-space: nowrap; }
+th { background: #f4f4f4; }
+td:first-child, th:first-child { text-align: left; }
+.fields td, .fields th { text-align: left; }
+figure { margin: 1em 0; }
+figcaption { font-weight: bold; margin-bottom: .3em; }
+svg { font-size: 11px; }
+.scroll { overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="generated">Generated {{.Generated.Format "2006-01-02 15:04:05 MST"}}</p>
+{{- if .Metadata}}
+<h2>Run metadata</h2>
+<table class="fields">
+{{- range .Metadata}}
+<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Flags}}
+<h2>Flags</h2>
+<table class="fields">
+{{- range .Flags}}
+<tr><th>--{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .Sections}}
+<h2>{{.Title}}</h2>
+{{- range .Charts}}
+<figure>
+<figcaption>{{.Title}}</figcaption>
+{{.SVG}}
+</figure>
+{{- end}}
+{{- range .Tables}}
+<h3>{{.Title}}</h3>
+<div class="scroll">
+<table>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+</div>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
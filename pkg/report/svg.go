@@ -0,0 +1,243 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+
+	"github.com/lfordyce/tiger/pkg/statistics"
+)
+
+const (
+	chartWidth   = 760
+	chartHeight  = 260
+	marginLeft   = 70
+	marginRight  = 20
+	marginTop    = 15
+	marginBottom = 35
+	boxRowHeight = 26
+	defaultColor = "#4e79a7"
+)
+
+// Group is a labelled set of latencies, e.g. all latencies of a hostname.
+type Group struct {
+	Label  string
+	Values []float64
+}
+
+// Point is a single point of a line.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Line is a named series of points, drawn in the given colour.
+type Line struct {
+	Name   string
+	Color  string
+	Points []Point
+}
+
+// canvas writes SVG elements and maps values to plot coordinates.
+type canvas struct {
+	sb            strings.Builder
+	width, height int
+	xMin, xMax    float64
+	yMin, yMax    float64
+}
+
+func newCanvas(width, height int) *canvas {
+	c := &canvas{width: width, height: height}
+	fmt.Fprintf(&c.sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		width, height, width, height)
+	return c
+}
+
+func (c *canvas) x(v float64) float64 {
+	plot := float64(c.width - marginLeft - marginRight)
+	if c.xMax == c.xMin {
+		return marginLeft + plot/2
+	}
+	return marginLeft + (v-c.xMin)/(c.xMax-c.xMin)*plot
+}
+
+func (c *canvas) y(v float64) float64 {
+	plot := float64(c.height - marginTop - marginBottom)
+	if c.yMax == c.yMin {
+		return marginTop + plot/2
+	}
+	return marginTop + plot - (v-c.yMin)/(c.yMax-c.yMin)*plot
+}
+
+func (c *canvas) line(x1, y1, x2, y2 float64, stroke string) {
+	fmt.Fprintf(&c.sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`, x1, y1, x2, y2, stroke)
+}
+
+func (c *canvas) rect(x, y, w, h float64, fill string, title string) {
+	fmt.Fprintf(&c.sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" stroke="#333" stroke-width="0.5">`,
+		x, y, w, h, fill)
+	fmt.Fprintf(&c.sb, `<title>%s</title></rect>`, template.HTMLEscapeString(title))
+}
+
+func (c *canvas) text(x, y float64, anchor string, s string) {
+	fmt.Fprintf(&c.sb, `<text x="%.1f" y="%.1f" text-anchor="%s">%s</text>`, x, y, anchor,
+		template.HTMLEscapeString(s))
+}
+
+// axes draws the axes with labels at both ends of each axis.
+func (c *canvas) axes(xLabel, yLabel string, xFormat, yFormat func(float64) string) {
+	bottom := float64(c.height - marginBottom)
+	right := float64(c.width - marginRight)
+	c.line(marginLeft, marginTop, marginLeft, bottom, "#333")
+	c.line(marginLeft, bottom, right, bottom, "#333")
+	if xFormat != nil {
+		c.text(marginLeft, bottom+14, "start", xFormat(c.xMin))
+		c.text(right, bottom+14, "end", xFormat(c.xMax))
+	}
+	if yFormat != nil {
+		c.text(marginLeft-5, marginTop+10, "end", yFormat(c.yMax))
+		c.text(marginLeft-5, bottom, "end", yFormat(c.yMin))
+	}
+	c.text(marginLeft+(right-marginLeft)/2, bottom+28, "middle", xLabel)
+	if yLabel != "" {
+		fmt.Fprintf(&c.sb, `<text x="12" y="%.1f" text-anchor="middle" transform="rotate(-90 12 %.1f)">%s</text>`,
+			marginTop+(bottom-marginTop)/2, marginTop+(bottom-marginTop)/2, template.HTMLEscapeString(yLabel))
+	}
+}
+
+func (c *canvas) html() template.HTML {
+	c.sb.WriteString(`</svg>`)
+	return template.HTML(c.sb.String()) // nolint:gosec // all text content is escaped
+}
+
+func ms(v float64) string {
+	return fmt.Sprintf("%.2fms", v)
+}
+
+// Histogram draws the distribution of the latencies in the given number of equally wide bins.
+func Histogram(values []float64, bins int) template.HTML {
+	c := newCanvas(chartWidth, chartHeight)
+	if len(values) == 0 || bins <= 0 {
+		return c.html()
+	}
+
+	c.xMin, c.xMax = statistics.Min(values), statistics.Max(values)
+	width := (c.xMax - c.xMin) / float64(bins)
+	counts := make([]int, bins)
+	for _, v := range values {
+		i := bins - 1
+		if width > 0 {
+			i = int((v - c.xMin) / width)
+		}
+		if i >= bins {
+			i = bins - 1
+		}
+		counts[i]++
+	}
+	c.yMax = float64(statistics.Max(counts))
+	if width == 0 {
+		// all latencies are equal, draw a single bar over the whole plot
+		c.xMax = c.xMin + 1
+		width = 1
+		counts = counts[bins-1:]
+	}
+
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		lo := c.xMin + float64(i)*width
+		x0, x1 := c.x(lo), c.x(lo+width)
+		c.rect(x0, c.y(float64(n)), x1-x0, c.y(0)-c.y(float64(n)), defaultColor,
+			fmt.Sprintf("%s - %s: %d", ms(lo), ms(lo+width), n))
+	}
+	c.axes("latency", "count", ms, func(v float64) string { return fmt.Sprint(int(v)) })
+	return c.html()
+}
+
+// BoxPlot draws a horizontal box plot for every group. Whiskers extend to the most extreme latencies within the
+// Tukey fences, latencies beyond are drawn as dots.
+func BoxPlot(groups []Group) template.HTML {
+	height := marginTop + marginBottom + boxRowHeight*len(groups)
+	c := newCanvas(chartWidth, height)
+	if len(groups) == 0 {
+		return c.html()
+	}
+
+	c.xMin, c.xMax = math.Inf(1), math.Inf(-1)
+	for _, g := range groups {
+		if len(g.Values) == 0 {
+			continue
+		}
+		c.xMin = math.Min(c.xMin, statistics.Min(g.Values))
+		c.xMax = math.Max(c.xMax, statistics.Max(g.Values))
+	}
+	if math.IsInf(c.xMin, 0) {
+		c.xMin, c.xMax = 0, 0
+	}
+
+	for i, g := range groups {
+		mid := float64(marginTop + boxRowHeight*i + boxRowHeight/2)
+		c.text(marginLeft-5, mid+4, "end", g.Label)
+		if len(g.Values) == 0 {
+			continue
+		}
+		q1, median, q3 := statistics.Percentile(g.Values, 25), statistics.Median(g.Values),
+			statistics.Percentile(g.Values, 75)
+		fences := statistics.IQRBounds(g.Values, 1.5)
+		lo, hi := q1, q3
+		for _, v := range g.Values {
+			if fences.Contains(v) {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			} else {
+				fmt.Fprintf(&c.sb, `<circle cx="%.1f" cy="%.1f" r="2" fill="#e15759"><title>%s</title></circle>`,
+					c.x(v), mid, ms(v))
+			}
+		}
+		half := float64(boxRowHeight) / 3
+		c.line(c.x(lo), mid, c.x(q1), mid, "#333")
+		c.line(c.x(q3), mid, c.x(hi), mid, "#333")
+		c.line(c.x(lo), mid-half/2, c.x(lo), mid+half/2, "#333")
+		c.line(c.x(hi), mid-half/2, c.x(hi), mid+half/2, "#333")
+		c.rect(c.x(q1), mid-half, math.Max(c.x(q3)-c.x(q1), 1), 2*half, defaultColor,
+			fmt.Sprintf("%s: q1 %s, median %s, q3 %s", g.Label, ms(q1), ms(median), ms(q3)))
+		c.line(c.x(median), mid-half, c.x(median), mid+half, "#fff")
+	}
+	c.axes("latency", "", ms, nil)
+	return c.html()
+}
+
+// LineChart draws the lines over a shared x axis, xFormat labels the ends of the x axis.
+func LineChart(lines []Line, xLabel string, xFormat func(float64) string) template.HTML {
+	c := newCanvas(chartWidth, chartHeight)
+	c.xMin, c.xMax = math.Inf(1), math.Inf(-1)
+	c.yMin, c.yMax = 0, math.Inf(-1)
+	for _, l := range lines {
+		for _, p := range l.Points {
+			c.xMin, c.xMax = math.Min(c.xMin, p.X), math.Max(c.xMax, p.X)
+			c.yMax = math.Max(c.yMax, p.Y)
+		}
+	}
+	if math.IsInf(c.xMin, 0) {
+		return c.html()
+	}
+
+	for i, l := range lines {
+		color := l.Color
+		if color == "" {
+			color = defaultColor
+		}
+		points := make([]string, 0, len(l.Points))
+		for _, p := range l.Points {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", c.x(p.X), c.y(p.Y)))
+		}
+		fmt.Fprintf(&c.sb, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`,
+			color, strings.Join(points, " "))
+		legend := float64(marginLeft + 10 + 90*i)
+		c.line(legend, marginTop+4, legend+15, marginTop+4, color)
+		c.text(legend+20, marginTop+8, "start", l.Name)
+	}
+	c.axes(xLabel, "latency", xFormat, ms)
+	return c.html()
+}
@@ -0,0 +1,57 @@
+package statistics
+
+// Distribution summarises a set of latencies.
+type Distribution struct {
+	Count int
+	Min   float64
+	P50   float64
+	P90   float64
+	P99   float64
+	Max   float64
+	Mean  float64
+}
+
+// Distribute computes the distribution of the given latencies.
+func Distribute(data []float64) Distribution {
+	return Distribution{
+		Count: len(data),
+		Min:   Min(data),
+		P50:   Percentile(data, 50),
+		P90:   Percentile(data, 90),
+		P99:   Percentile(data, 99),
+		Max:   Max(data),
+		Mean:  Mean(data),
+	}
+}
+
+// CorrectLatencies returns the response times in milliseconds of the samples corrected for coordinated omission.
+// Samples paced by a schedule are measured from the time they were due, see Sample.Corrected. A closed-loop worker
+// only sends its next request once the previous one completed, so the requests it would have sent while waiting for
+// a slow response are missing altogether. Like HdrHistogram's recordValueWithExpectedInterval, a response slower than
+// the expected interval of its worker, the median response time of the worker, adds the response times of the
+// missing requests, one per expected interval: value-interval, value-2*interval, ... down to the interval.
+func CorrectLatencies(samples []Sample) []float64 {
+	byWorker := make(map[int][]float64)
+	corrected := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		if s.Intended.IsZero() {
+			byWorker[s.WorkerID] = append(byWorker[s.WorkerID], s.Corrected())
+		} else {
+			corrected = append(corrected, s.Corrected())
+		}
+	}
+
+	for _, values := range byWorker {
+		interval := Median(values)
+		for _, v := range values {
+			corrected = append(corrected, v)
+			if interval <= 0 {
+				continue
+			}
+			for k := 1; k < int(v/interval); k++ {
+				corrected = append(corrected, v-float64(k)*interval)
+			}
+		}
+	}
+	return corrected
+}
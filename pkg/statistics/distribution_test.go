@@ -0,0 +1,90 @@
+package statistics
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestDistribute(t *testing.T) {
+	d := Distribute([]float64{5, 1, 4, 2, 3})
+	want := Distribution{Count: 5, Min: 1, P50: 3, P90: 4.6, P99: 4.96, Max: 5, Mean: 3}
+	if d.Count != want.Count || d.Min != want.Min || d.P50 != want.P50 || d.Max != want.Max || d.Mean != want.Mean {
+		t.Errorf("Distribute() => %+v, want %+v", d, want)
+	}
+	if diff := d.P90 - want.P90; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Distribute() p90 => %.2f, want %.2f", d.P90, want.P90)
+	}
+	if diff := d.P99 - want.P99; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Distribute() p99 => %.2f, want %.2f", d.P99, want.P99)
+	}
+}
+
+func TestSampleCorrected(t *testing.T) {
+	intended := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := Sample{
+		Intended:  intended,
+		Started:   intended.Add(40 * time.Millisecond),
+		Completed: intended.Add(50 * time.Millisecond),
+	}
+	if got := s.Delay(); got != 40*time.Millisecond {
+		t.Errorf("Delay() => %s, want 40ms", got)
+	}
+	if got := s.Corrected(); got != 50 {
+		t.Errorf("Corrected() => %.1f, want 50", got)
+	}
+
+	s.Intended = time.Time{}
+	if got := s.Delay(); got != 0 {
+		t.Errorf("Delay() without intended start => %s, want 0", got)
+	}
+	if got := s.Corrected(); got != 10 {
+		t.Errorf("Corrected() without intended start => %.1f, want 10", got)
+	}
+}
+
+func TestCorrectLatencies(t *testing.T) {
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	closedLoop := func(worker int, ms ...int) []Sample {
+		samples := make([]Sample, 0, len(ms))
+		for _, m := range ms {
+			samples = append(samples, Sample{
+				WorkerID: worker, Started: start, Completed: start.Add(time.Duration(m) * time.Millisecond),
+			})
+		}
+		return samples
+	}
+
+	tests := []struct {
+		name    string
+		samples []Sample
+		want    []float64
+	}{
+		{"no samples", nil, []float64{}},
+		{"uniform", closedLoop(0, 2, 2, 2), []float64{2, 2, 2}},
+		{"below twice the interval", closedLoop(0, 2, 2, 3), []float64{2, 2, 3}},
+		// the worker missed four requests while waiting for the slow one, due every 2ms
+		{"stall", closedLoop(0, 2, 2, 2, 10), []float64{2, 2, 2, 2, 4, 6, 8, 10}},
+		// every worker is corrected by its own interval
+		{"per worker", append(closedLoop(0, 2, 2, 6), closedLoop(1, 6, 6, 6)...), []float64{2, 2, 2, 4, 6, 6, 6, 6}},
+		{"zero interval", closedLoop(0, 0, 0, 5), []float64{0, 0, 5}},
+		{"paced", []Sample{{
+			WorkerID: 0, Intended: start, Started: start.Add(40 * time.Millisecond),
+			Completed: start.Add(50 * time.Millisecond),
+		}}, []float64{50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CorrectLatencies(tt.samples)
+			sort.Float64s(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("CorrectLatencies() => %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if diff := got[i] - tt.want[i]; diff > 1e-9 || diff < -1e-9 {
+					t.Fatalf("CorrectLatencies() => %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
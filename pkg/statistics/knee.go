@@ -0,0 +1,31 @@
+package statistics
+
+// Knee finds the knee of a concave, increasing curve, such as the throughput against the concurrency, with the
+// Kneedle method: both axes are scaled to [0, 1] and the knee is the point furthest above the straight line through
+// the first and the last point. The x values must be increasing. It returns the index of the knee, or -1 if there
+// are fewer than 3 points or no point lies more than minDistance above the line, e.g. because the curve is linear.
+func Knee(x, y []float64, minDistance float64) int {
+	n := len(x)
+	if n < 3 || len(y) != n {
+		return -1
+	}
+	xMin, xMax := x[0], x[n-1]
+	yMin, yMax := Min(y), Max(y)
+	if xMax <= xMin || yMax <= yMin {
+		return -1
+	}
+	scale := func(v, lo, hi float64) float64 {
+		return (v - lo) / (hi - lo)
+	}
+
+	first, last := scale(y[0], yMin, yMax), scale(y[n-1], yMin, yMax)
+	knee, furthest := -1, minDistance
+	for i := 1; i < n-1; i++ {
+		xs := scale(x[i], xMin, xMax)
+		line := first + (last-first)*xs
+		if d := scale(y[i], yMin, yMax) - line; d > furthest {
+			knee, furthest = i, d
+		}
+	}
+	return knee
+}
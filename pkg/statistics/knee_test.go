@@ -0,0 +1,26 @@
+package statistics
+
+import (
+	"testing"
+)
+
+func TestKnee(t *testing.T) {
+	workers := []float64{1, 2, 4, 8, 16, 32}
+	cases := [...]struct {
+		name string
+		x, y []float64
+		out  int
+	}{
+		{"saturating", workers, []float64{100, 195, 380, 700, 760, 770}, 3},
+		{"declining", workers, []float64{100, 190, 350, 420, 380, 300}, 3},
+		{"linear", workers, []float64{100, 200, 400, 800, 1600, 3200}, -1},
+		{"flat", workers, []float64{100, 100, 100, 100, 100, 100}, -1},
+		{"too short", workers[:2], []float64{100, 200}, -1},
+		{"mismatch", workers, []float64{100, 200}, -1},
+	}
+	for _, tst := range cases {
+		if out := Knee(tst.x, tst.y, 0.05); out != tst.out {
+			t.Errorf("Knee(%s) => %d != %d", tst.name, out, tst.out)
+		}
+	}
+}
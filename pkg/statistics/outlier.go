@@ -0,0 +1,91 @@
+package statistics
+
+import (
+	"math"
+
+	"golang.org/x/exp/slices"
+)
+
+// madScale turns the median absolute deviation into a consistent estimator of the standard deviation of normally
+// distributed data.
+const madScale = 1.4826
+
+// Bounds is the range of values that are not considered outliers.
+type Bounds struct {
+	Lower float64
+	Upper float64
+}
+
+// Contains reports whether the value lies within the bounds.
+func (b Bounds) Contains(v float64) bool {
+	return v >= b.Lower && v <= b.Upper
+}
+
+// IQRBounds gets the Tukey fences of a slice of numbers: values more than k interquartile ranges below the first or
+// above the third quartile are outliers, k is commonly 1.5.
+func IQRBounds(data []float64, k float64) Bounds {
+	q1, q3 := Percentile(data, 25), Percentile(data, 75)
+	iqr := q3 - q1
+	return Bounds{Lower: q1 - k*iqr, Upper: q3 + k*iqr}
+}
+
+// MAD gets the median absolute deviation of a slice of numbers
+func MAD(data []float64) float64 {
+	median := Median(data)
+	deviations := make([]float64, len(data))
+	for i, d := range data {
+		deviations[i] = math.Abs(d - median)
+	}
+	return Median(deviations)
+}
+
+// MADBounds gets the bounds of values within k scaled median absolute deviations of the median, k is commonly 3.
+func MADBounds(data []float64, k float64) Bounds {
+	median := Median(data)
+	mad := madScale * MAD(data)
+	return Bounds{Lower: median - k*mad, Upper: median + k*mad}
+}
+
+// Trim removes the given fraction (0-0.5) of the lowest and of the highest numbers from a slice. The returned slice
+// is sorted.
+func Trim(data []float64, fraction float64) []float64 {
+	sorted := make([]float64, len(data))
+	copy(sorted, data)
+	slices.Sort(sorted)
+
+	cut := trimCount(len(sorted), fraction)
+	return sorted[cut : len(sorted)-cut]
+}
+
+// Winsorize replaces the given fraction (0-0.5) of the lowest and of the highest numbers of a slice with the closest
+// remaining number. The returned slice is sorted.
+func Winsorize(data []float64, fraction float64) []float64 {
+	sorted := make([]float64, len(data))
+	copy(sorted, data)
+	slices.Sort(sorted)
+
+	cut := trimCount(len(sorted), fraction)
+	for i := 0; i < cut; i++ {
+		sorted[i] = sorted[cut]
+		sorted[len(sorted)-1-i] = sorted[len(sorted)-1-cut]
+	}
+	return sorted
+}
+
+// TrimmedMean gets the average of a slice of numbers without the given fraction (0-0.5) of the lowest and of the
+// highest numbers.
+func TrimmedMean(data []float64, fraction float64) float64 {
+	return Mean(Trim(data, fraction))
+}
+
+// trimCount is the number of values cut from each tail, at least one value always remains.
+func trimCount(n int, fraction float64) int {
+	if fraction <= 0 || n == 0 {
+		return 0
+	}
+	cut := int(float64(n) * fraction)
+	if 2*cut >= n {
+		cut = (n - 1) / 2
+	}
+	return cut
+}
@@ -0,0 +1,73 @@
+package statistics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIQRBounds(t *testing.T) {
+	data := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 2000}
+	b := IQRBounds(data, 1.5)
+	if b.Contains(2000) {
+		t.Errorf("IQRBounds() => %+v contains the outlier", b)
+	}
+	for _, v := range data[:9] {
+		if !b.Contains(v) {
+			t.Errorf("IQRBounds() => %+v does not contain %.1f", b, v)
+		}
+	}
+}
+
+func TestMAD(t *testing.T) {
+	cases := [...]struct {
+		in  []float64
+		out float64
+	}{
+		{[]float64{1, 1, 2, 2, 4, 6, 9}, 1},
+		{[]float64{3, 3, 3}, 0},
+		{[]float64{}, 0},
+	}
+	for _, tst := range cases {
+		if got := MAD(tst.in); got != tst.out {
+			t.Errorf("MAD(%.1f) => %.1f != %.1f", tst.in, got, tst.out)
+		}
+	}
+}
+
+func TestMADBounds(t *testing.T) {
+	data := []float64{10, 11, 12, 11, 10, 12, 11, 2000}
+	b := MADBounds(data, 3)
+	if b.Contains(2000) {
+		t.Errorf("MADBounds() => %+v contains the outlier", b)
+	}
+	if !b.Contains(12) || !b.Contains(10) {
+		t.Errorf("MADBounds() => %+v excludes regular values", b)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	data := []float64{9, 1, 5, 3, 7, 2, 8, 4, 6, 100}
+	if got, want := Trim(data, 0.1), []float64{2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Trim() => %v, want %v", got, want)
+	}
+	if got := Trim(data, 0); len(got) != len(data) {
+		t.Errorf("Trim() without fraction removed values: %v", got)
+	}
+	if got := Trim([]float64{1, 2, 3}, 0.5); !reflect.DeepEqual(got, []float64{2}) {
+		t.Errorf("Trim() of half => %v, want [2]", got)
+	}
+	if got := TrimmedMean(data, 0.1); got != 5.5 {
+		t.Errorf("TrimmedMean() => %.2f, want 5.5", got)
+	}
+}
+
+func TestWinsorize(t *testing.T) {
+	data := []float64{9, 1, 5, 3, 7, 2, 8, 4, 6, 100}
+	want := []float64{2, 2, 3, 4, 5, 6, 7, 8, 9, 9}
+	if got := Winsorize(data, 0.1); !reflect.DeepEqual(got, want) {
+		t.Errorf("Winsorize() => %v, want %v", got, want)
+	}
+	if data[9] != 100 {
+		t.Errorf("Winsorize() modified its input")
+	}
+}
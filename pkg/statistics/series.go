@@ -0,0 +1,50 @@
+package statistics
+
+import "time"
+
+// Interval summarises the samples completed within one wall-clock interval of a run.
+type Interval struct {
+	Start time.Time
+	Count int
+	QPS   float64
+	P50   float64
+	P95   float64
+	Max   float64
+}
+
+// Series buckets the samples by their completion time into consecutive intervals of the given width, starting at
+// the first completed sample. Intervals without any completed sample are kept, so gaps in throughput stay visible.
+func Series(samples []Sample, width time.Duration) []Interval {
+	if len(samples) == 0 || width <= 0 {
+		return nil
+	}
+
+	first, last := samples[0].Completed, samples[0].Completed
+	for _, s := range samples[1:] {
+		if s.Completed.Before(first) {
+			first = s.Completed
+		}
+		if s.Completed.After(last) {
+			last = s.Completed
+		}
+	}
+
+	buckets := make([][]float64, int(last.Sub(first)/width)+1)
+	for _, s := range samples {
+		i := int(s.Completed.Sub(first) / width)
+		buckets[i] = append(buckets[i], s.Elapsed)
+	}
+
+	series := make([]Interval, 0, len(buckets))
+	for i, b := range buckets {
+		series = append(series, Interval{
+			Start: first.Add(time.Duration(i) * width),
+			Count: len(b),
+			QPS:   float64(len(b)) / width.Seconds(),
+			P50:   Percentile(b, 50),
+			P95:   Percentile(b, 95),
+			Max:   Max(b),
+		})
+	}
+	return series
+}
@@ -0,0 +1,54 @@
+package statistics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeries(t *testing.T) {
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration, elapsed float64) Sample {
+		return Sample{Completed: start.Add(d), Elapsed: elapsed}
+	}
+	samples := []Sample{
+		at(1500*time.Millisecond, 4),
+		at(0, 1),
+		at(200*time.Millisecond, 3),
+		at(3100*time.Millisecond, 8),
+		at(1*time.Second, 2),
+	}
+
+	series := Series(samples, time.Second)
+	if len(series) != 4 {
+		t.Fatalf("Series() returned %d intervals, want 4", len(series))
+	}
+
+	want := []struct {
+		count int
+		max   float64
+	}{{2, 3}, {2, 4}, {0, 0}, {1, 8}}
+	for i, w := range want {
+		got := series[i]
+		if !got.Start.Equal(start.Add(time.Duration(i) * time.Second)) {
+			t.Errorf("interval %d starts at %s", i, got.Start)
+		}
+		if got.Count != w.count || got.Max != w.max {
+			t.Errorf("interval %d => count %d max %.1f, want count %d max %.1f", i, got.Count, got.Max, w.count, w.max)
+		}
+		if got.QPS != float64(w.count) {
+			t.Errorf("interval %d => qps %.1f, want %d", i, got.QPS, w.count)
+		}
+	}
+	if series[0].P50 != 2 {
+		t.Errorf("interval 0 => p50 %.1f, want 2", series[0].P50)
+	}
+}
+
+func TestSeriesEmpty(t *testing.T) {
+	if got := Series(nil, time.Second); got != nil {
+		t.Errorf("Series(nil) => %v, want nil", got)
+	}
+	if got := Series([]Sample{{}}, 0); got != nil {
+		t.Errorf("Series() with zero width => %v, want nil", got)
+	}
+}
@@ -14,6 +14,43 @@ type Sample struct {
 	HostnameID string
 	StartTime  time.Time
 	EndTime    time.Time
+	// Line and Offset locate the input row the measured request was read from.
+	Line   int
+	Offset int64
+	// BackendPID is the process id of the database backend that executed the request.
+	BackendPID uint32
+	// Target is the name of the database the request was executed against.
+	Target string
+	// Cell describes the matrix cell the request was executed for.
+	Cell string
+	// Source names the input file the request was read from.
+	Source string
+	// Intended is the time the request was due at according to the schedule it was paced by, zero if it wasn't
+	// paced. Started is the time it actually started.
+	Intended time.Time
+	Started  time.Time
+	// Completed is the wall-clock time the request finished at.
+	Completed time.Time
+	// Stage is the number of the load stage the request started in, starting at 1. It is 0 without stages.
+	Stage int
+}
+
+// Delay is the time the request started behind its schedule, e.g. because it was queued behind a slow query.
+func (s Sample) Delay() time.Duration {
+	if s.Intended.IsZero() || s.Started.Before(s.Intended) {
+		return 0
+	}
+	return s.Started.Sub(s.Intended)
+}
+
+// Corrected is the response time in milliseconds measured from the time the request was due, which accounts for
+// the coordinated omission of a closed-loop benchmark. Without a schedule it is the response time.
+func (s Sample) Corrected() float64 {
+	start := s.Intended
+	if start.IsZero() {
+		start = s.Started
+	}
+	return float64(s.Completed.Sub(start)) / float64(time.Millisecond)
 }
 
 // GroupedSample represents all the measurements grouped by hostname.
@@ -49,24 +86,92 @@ func Mean[T Number](data []T) float64 {
 	return sum / float64(len(data))
 }
 
-// Median gets the median number in a slice of numbers
+// Median gets the median number in a slice of numbers. It selects the middle numbers instead of sorting, so it
+// runs in linear time on average.
 func Median[T Number](data []T) float64 {
 	dataCopy := make([]T, len(data))
 	copy(dataCopy, data)
 
-	slices.Sort(dataCopy)
-
-	var median float64
 	l := len(dataCopy)
 	if l == 0 {
 		return 0
-	} else if l%2 == 0 {
-		median = Mean(dataCopy[l/2-1 : l/2+1])
-	} else {
-		median = float64(dataCopy[l/2])
 	}
+	upper := float64(nth(dataCopy, l/2))
+	if l%2 != 0 {
+		return upper
+	}
+	// nth leaves the numbers below the upper middle one in front of it, the lower middle one is the largest of them
+	return (float64(Max(dataCopy[:l/2])) + upper) / 2
+}
+
+// nth reorders data so that the number at index k is the one that would be there if data were sorted, with no
+// larger number in front of it and no smaller number behind it, and returns that number (quickselect).
+func nth[T Number](data []T, k int) T {
+	lo, hi := 0, len(data)-1
+	for lo < hi {
+		// median of three as pivot keeps sorted input linear
+		mid := lo + (hi-lo)/2
+		if data[mid] < data[lo] {
+			data[mid], data[lo] = data[lo], data[mid]
+		}
+		if data[hi] < data[lo] {
+			data[hi], data[lo] = data[lo], data[hi]
+		}
+		if data[hi] < data[mid] {
+			data[hi], data[mid] = data[mid], data[hi]
+		}
+		pivot := data[mid]
 
-	return median
+		i, j := lo, hi
+		for i <= j {
+			for data[i] < pivot {
+				i++
+			}
+			for data[j] > pivot {
+				j--
+			}
+			if i <= j {
+				data[i], data[j] = data[j], data[i]
+				i++
+				j--
+			}
+		}
+		switch {
+		case k <= j:
+			hi = j
+		case k >= i:
+			lo = i
+		default:
+			return data[k]
+		}
+	}
+	return data[k]
+}
+
+// Percentile gets the p-th percentile (0-100) of a slice of numbers, interpolating linearly between the closest
+// ranks.
+func Percentile[T Number](data []T, p float64) float64 {
+	l := len(data)
+	if l == 0 {
+		return 0
+	}
+	dataCopy := make([]T, l)
+	copy(dataCopy, data)
+	slices.Sort(dataCopy)
+
+	if p <= 0 {
+		return float64(dataCopy[0])
+	}
+	if p >= 100 {
+		return float64(dataCopy[l-1])
+	}
+	rank := p / 100 * float64(l-1)
+	lower := int(rank)
+	if lower+1 >= l {
+		return float64(dataCopy[lower])
+	}
+	frac := rank - float64(lower)
+	return float64(dataCopy[lower]) + frac*(float64(dataCopy[lower+1])-float64(dataCopy[lower]))
 }
 
 // Max finds the highest number in a slice
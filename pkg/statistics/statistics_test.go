@@ -1,8 +1,10 @@
 package statistics
 
 import (
+	"math"
 	"math/rand"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 )
@@ -42,6 +44,27 @@ func TestMedian(t *testing.T) {
 	}
 }
 
+func TestMedianSelection(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 1; n < 200; n++ {
+		data := make([]int, n)
+		for i := range data {
+			// few distinct values, so that duplicates of the pivot are common
+			data[i] = rng.Intn(n/4 + 1)
+		}
+		sorted := make([]int, n)
+		copy(sorted, data)
+		sort.Ints(sorted)
+		want := float64(sorted[n/2])
+		if n%2 == 0 {
+			want = float64(sorted[n/2-1]+sorted[n/2]) / 2
+		}
+		if got := Median(data); got != want {
+			t.Fatalf("Median(%v) => %.1f != %.1f", data, got, want)
+		}
+	}
+}
+
 func BenchmarkMedianSmallFloatSlice(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = Median(makeFloatSlice(5))
@@ -173,3 +196,24 @@ func BenchmarkMaxLargeFloatSlice(b *testing.B) {
 		_ = Max(lf)
 	}
 }
+
+func TestPercentile(t *testing.T) {
+	cases := [...]struct {
+		in  []float64
+		p   float64
+		out float64
+	}{
+		{[]float64{5, 3, 4, 2, 1}, 50, 3.0},
+		{[]float64{1, 2, 3, 4}, 50, 2.5},
+		{[]float64{1, 2, 3, 4, 5}, 95, 4.8},
+		{[]float64{1, 2, 3, 4, 5}, 100, 5.0},
+		{[]float64{1, 2, 3, 4, 5}, 0, 1.0},
+		{[]float64{7}, 95, 7.0},
+		{[]float64{}, 50, 0},
+	}
+	for _, tst := range cases {
+		if got := Percentile(tst.in, tst.p); math.Abs(got-tst.out) > 1e-9 {
+			t.Errorf("Percentile(%.1f, %.0f) => %.2f != %.2f", tst.in, tst.p, got, tst.out)
+		}
+	}
+}
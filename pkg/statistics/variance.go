@@ -0,0 +1,104 @@
+package statistics
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Welford computes the mean and variance of a stream of numbers in a single pass, without keeping the numbers
+// around.
+type Welford struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+// Add adds a number to the stream.
+func (w *Welford) Add(x float64) {
+	w.n++
+	delta := x - w.mean
+	w.mean += delta / float64(w.n)
+	w.m2 += delta * (x - w.mean)
+}
+
+// Count is the amount of numbers added to the stream.
+func (w *Welford) Count() int {
+	return w.n
+}
+
+// Mean is the average of all numbers added to the stream.
+func (w *Welford) Mean() float64 {
+	return w.mean
+}
+
+// Variance is the sample variance of all numbers added to the stream.
+func (w *Welford) Variance() float64 {
+	if w.n < 2 {
+		return 0
+	}
+	return w.m2 / float64(w.n-1)
+}
+
+// StdDev is the sample standard deviation of all numbers added to the stream.
+func (w *Welford) StdDev() float64 {
+	return math.Sqrt(w.Variance())
+}
+
+// StdDev gets the sample standard deviation of a slice of numbers
+func StdDev[T Number](data []T) float64 {
+	var w Welford
+	for _, d := range data {
+		w.Add(float64(d))
+	}
+	return w.StdDev()
+}
+
+// CV gets the coefficient of variation, the standard deviation relative to the mean, of a slice of numbers
+func CV[T Number](data []T) float64 {
+	var w Welford
+	for _, d := range data {
+		w.Add(float64(d))
+	}
+	if w.Mean() == 0 {
+		return 0
+	}
+	return w.StdDev() / w.Mean()
+}
+
+// Bootstrap estimates the confidence interval of a statistic at the given level (0-100) with the percentile
+// bootstrap: the statistic is computed for resamples drawn with replacement from the data. The same seed
+// reproduces the same interval.
+//
+// Every resample costs a pass over size numbers, so size caps the resamples of large data sets (0 uses the size of
+// the data). Smaller resamples spread wider, the interval is scaled back to the size of the data around the
+// statistic of the data (m out of n bootstrap), which holds for statistics such as the mean and median whose error
+// shrinks with the square root of the sample size.
+func Bootstrap(
+	data []float64, stat func([]float64) float64, level float64, resamples int, size int, seed int64,
+) (lo float64, hi float64) {
+	if len(data) == 0 || resamples <= 0 {
+		return 0, 0
+	}
+	if size <= 0 || size > len(data) {
+		size = len(data)
+	}
+
+	rng := rand.New(rand.NewSource(seed)) // nolint:gosec
+	estimates := make([]float64, resamples)
+	resample := make([]float64, size)
+	for i := range estimates {
+		for j := range resample {
+			resample[j] = data[rng.Intn(len(data))]
+		}
+		estimates[i] = stat(resample)
+	}
+
+	tail := (100 - level) / 2
+	lo, hi = Percentile(estimates, tail), Percentile(estimates, 100-tail)
+	if size < len(data) {
+		center := stat(data)
+		scale := math.Sqrt(float64(size) / float64(len(data)))
+		lo, hi = center+(lo-center)*scale, center+(hi-center)*scale
+	}
+	return lo, hi
+}
@@ -0,0 +1,90 @@
+package statistics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWelford(t *testing.T) {
+	var w Welford
+	for _, x := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		w.Add(x)
+	}
+	if w.Count() != 8 {
+		t.Errorf("Count() => %d, want 8", w.Count())
+	}
+	if w.Mean() != 5 {
+		t.Errorf("Mean() => %.4f, want 5", w.Mean())
+	}
+	if got, want := w.Variance(), 32.0/7; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Variance() => %.4f, want %.4f", got, want)
+	}
+}
+
+func TestStdDev(t *testing.T) {
+	cases := [...]struct {
+		in  []float64
+		out float64
+	}{
+		{[]float64{1, 2, 3, 4, 5}, math.Sqrt(2.5)},
+		{[]float64{3, 3, 3}, 0},
+		{[]float64{1}, 0},
+		{[]float64{}, 0},
+	}
+	for _, tst := range cases {
+		if got := StdDev(tst.in); math.Abs(got-tst.out) > 1e-9 {
+			t.Errorf("StdDev(%.1f) => %.4f != %.4f", tst.in, got, tst.out)
+		}
+	}
+}
+
+func TestCV(t *testing.T) {
+	if got, want := CV([]float64{1, 2, 3, 4, 5}), math.Sqrt(2.5)/3; math.Abs(got-want) > 1e-9 {
+		t.Errorf("CV() => %.4f != %.4f", got, want)
+	}
+	if got := CV([]float64{0, 0}); got != 0 {
+		t.Errorf("CV() of zeros => %.4f, want 0", got)
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	data := makeFloatSlice(100)
+	mean := Mean(data)
+
+	lo, hi := Bootstrap(data, Mean[float64], 95, 1000, 0, 1)
+	if lo > mean || hi < mean {
+		t.Errorf("Bootstrap() => [%.2f, %.2f] does not contain the mean %.2f", lo, hi, mean)
+	}
+	if lo == hi {
+		t.Errorf("Bootstrap() => empty interval [%.2f, %.2f]", lo, hi)
+	}
+
+	lo2, hi2 := Bootstrap(data, Mean[float64], 95, 1000, 0, 1)
+	if lo != lo2 || hi != hi2 {
+		t.Errorf("Bootstrap() is not reproducible with the same seed")
+	}
+
+	narrowLo, narrowHi := Bootstrap(data, Mean[float64], 50, 1000, 0, 1)
+	if narrowHi-narrowLo >= hi-lo {
+		t.Errorf("Bootstrap() 50%% interval is not narrower than the 95%% interval")
+	}
+
+	if lo, hi := Bootstrap(nil, Median[float64], 95, 1000, 0, 1); lo != 0 || hi != 0 {
+		t.Errorf("Bootstrap(nil) => [%.2f, %.2f], want [0, 0]", lo, hi)
+	}
+}
+
+func TestBootstrapCapped(t *testing.T) {
+	data := makeFloatSlice(20000)
+	for _, stat := range []func([]float64) float64{Mean[float64], Median[float64]} {
+		fullLo, fullHi := Bootstrap(data, stat, 95, 200, 0, 1)
+		lo, hi := Bootstrap(data, stat, 95, 200, 2000, 1)
+		if center := stat(data); lo > center || hi < center {
+			t.Errorf("Bootstrap() capped => [%.2f, %.2f] does not contain %.2f", lo, hi, center)
+		}
+		// the capped interval is scaled back to the size of the data, so its width matches the full bootstrap
+		if width, full := hi-lo, fullHi-fullLo; math.Abs(width-full) > 0.25*full {
+			t.Errorf("Bootstrap() capped width %.2f differs from the full width %.2f", width, full)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package table
+
+import (
+	stdcsv "encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Formats the tables can be rendered in, see NewRenderer.
+const (
+	FormatText     = "text"
+	FormatBox      = "box"
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists all formats supported by NewRenderer.
+var Formats = []string{FormatText, FormatBox, FormatCSV, FormatTSV, FormatJSON, FormatMarkdown} // nolint:gochecknoglobals
+
+// minColumnWidth is the narrowest a column is shrunk to when fitting a table into a maximum width.
+const minColumnWidth = 4
+
+// Renderer writes a table in a specific format.
+type Renderer interface {
+	Render(w io.Writer, t *Table) error
+}
+
+// Overflow controls how values wider than their column are shown.
+type Overflow int
+
+const (
+	// OverflowEllipsis cuts the value and marks the cut with an ellipsis.
+	OverflowEllipsis Overflow = iota
+	// OverflowWrap continues the value on the next lines.
+	OverflowWrap
+)
+
+// Options configure the text and box renderers.
+type Options struct {
+	// MaxWidth is the width tables are fit into, 0 does not limit the width.
+	MaxWidth int
+	Overflow Overflow
+	// Color applies the Table.Style to the cells.
+	Color bool
+}
+
+// NewRenderer returns the renderer of the format.
+func NewRenderer(format string, opts Options) (Renderer, error) {
+	switch format {
+	case FormatText:
+		return TextRenderer(opts), nil
+	case FormatBox:
+		return BoxRenderer(opts), nil
+	case FormatCSV:
+		return DelimitedRenderer{Comma: ','}, nil
+	case FormatTSV:
+		return DelimitedRenderer{Comma: '\t'}, nil
+	case FormatJSON:
+		return JSONRenderer{}, nil
+	case FormatMarkdown:
+		return MarkdownRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("table.NewRenderer: unknown format '%s', possible values are %s", format,
+			strings.Join(Formats, ","))
+	}
+}
+
+// TextRenderer writes the table as space-padded columns.
+type TextRenderer Options
+
+// Render implements Renderer.
+func (r TextRenderer) Render(w io.Writer, t *Table) error {
+	columns := t.visible()
+	spacing := utf8.RuneCountInString(t.ColumnSpacing)
+	widths := t.fit(columns, r.MaxWidth, spacing*len(columns))
+
+	// write renders the data row with the index, or the header with -1
+	write := func(index int, row Row) error {
+		for _, line := range cellLines(row, columns, widths, r.Overflow) {
+			var sb strings.Builder
+			for i, c := range columns {
+				cell := pad(line[i], widths[i], t.Columns[c].LeftAlign)
+				if index >= 0 {
+					cell = t.style(index, c, cell, r.Color)
+				}
+				sb.WriteString(cell)
+				sb.WriteString(t.ColumnSpacing)
+			}
+			sb.WriteString("\n")
+			if _, err := io.WriteString(w, sb.String()); err != nil {
+				return fmt.Errorf("table.TextRenderer: failed to write row %w", err)
+			}
+		}
+		return nil
+	}
+
+	if err := write(-1, t.headerRow()); err != nil {
+		return err
+	}
+	t.sort()
+	for i, row := range t.Data {
+		if err := write(i, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BoxRenderer writes the table framed with Unicode box-drawing characters.
+type BoxRenderer Options
+
+// Render implements Renderer.
+func (r BoxRenderer) Render(w io.Writer, t *Table) error {
+	columns := t.visible()
+	// every column is framed by "│ " and " ", the table is closed by a final "│"
+	widths := t.fit(columns, r.MaxWidth, 3*len(columns)+1)
+
+	var sb strings.Builder
+	border := func(left, middle, right string) {
+		sb.WriteString(left)
+		for i, width := range widths {
+			if i > 0 {
+				sb.WriteString(middle)
+			}
+			sb.WriteString(strings.Repeat("─", width+2))
+		}
+		sb.WriteString(right + "\n")
+	}
+	// row renders the data row with the index, or the header with -1
+	row := func(index int, row Row) {
+		for _, line := range cellLines(row, columns, widths, r.Overflow) {
+			for i, c := range columns {
+				cell := pad(line[i], widths[i], t.Columns[c].LeftAlign)
+				if index >= 0 {
+					cell = t.style(index, c, cell, r.Color)
+				}
+				sb.WriteString("│ " + cell + " ")
+			}
+			sb.WriteString("│\n")
+		}
+	}
+
+	border("┌", "┬", "┐")
+	row(-1, t.headerRow())
+	border("├", "┼", "┤")
+	t.sort()
+	for i, data := range t.Data {
+		row(i, data)
+	}
+	border("└", "┴", "┘")
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("table.BoxRenderer: failed to write table %w", err)
+	}
+	return nil
+}
+
+// DelimitedRenderer writes the table as CSV, or TSV with a tab as Comma.
+type DelimitedRenderer struct {
+	Comma rune
+}
+
+// Render implements Renderer.
+func (r DelimitedRenderer) Render(w io.Writer, t *Table) error {
+	cw := stdcsv.NewWriter(w)
+	cw.Comma = r.Comma
+	if err := cw.Write(t.Headers()); err != nil {
+		return fmt.Errorf("table.DelimitedRenderer: failed to write header %w", err)
+	}
+	if err := cw.WriteAll(t.Rows()); err != nil {
+		return fmt.Errorf("table.DelimitedRenderer: failed to write rows %w", err)
+	}
+	return nil
+}
+
+// JSONRenderer writes the table as JSON array with an object per row, keyed by the column headers. Numeric columns
+// are written as numbers, durations in milliseconds.
+type JSONRenderer struct{}
+
+// Render implements Renderer.
+func (r JSONRenderer) Render(w io.Writer, t *Table) error {
+	objects, err := t.JSON()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append(objects, '\n')); err != nil {
+		return fmt.Errorf("table.JSONRenderer: failed to write table %w", err)
+	}
+	return nil
+}
+
+// JSON encodes the visible columns of the sorted rows as JSON array of objects, keeping the column order.
+func (t *Table) JSON() ([]byte, error) {
+	columns := t.visible()
+	t.sort()
+
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := range t.Data {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("{")
+		for j, c := range columns {
+			if j > 0 {
+				sb.WriteString(",")
+			}
+			key, err := json.Marshal(t.Columns[c].Header)
+			if err != nil {
+				return nil, fmt.Errorf("table.JSON: failed to encode header %w", err)
+			}
+			value, err := json.Marshal(t.jsonValue(i, c))
+			if err != nil {
+				return nil, fmt.Errorf("table.JSON: failed to encode value %w", err)
+			}
+			sb.Write(key)
+			sb.WriteString(":")
+			sb.Write(value)
+		}
+		sb.WriteString("}")
+	}
+	sb.WriteString("]")
+	return []byte(sb.String()), nil
+}
+
+// jsonValue is the number behind int and float cells and the milliseconds of duration cells, all other cells are
+// written as their text.
+func (t *Table) jsonValue(row, column int) interface{} {
+	switch t.Columns[column].Type {
+	case TypeInt, TypeFloat:
+		if v, ok := t.value(row, column); ok {
+			return v
+		}
+	case TypeDuration:
+		if v, ok := t.value(row, column); ok {
+			return v / float64(time.Millisecond)
+		}
+	}
+	return t.Data[row][column]
+}
+
+// style wraps the padded text of a cell in the SGR parameters returned by Table.Style.
+func (t *Table) style(row, column int, text string, color bool) string {
+	if !color || t.Style == nil {
+		return text
+	}
+	cell := Cell{Column: t.Columns[column], Index: column, Text: t.Data[row][column], Row: t.Data[row]}
+	if row < len(t.Raw) {
+		cell.RawRow = t.Raw[row]
+		if column < len(t.Raw[row]) {
+			cell.Raw = t.Raw[row][column]
+		}
+	}
+	sgr := t.Style(cell)
+	if sgr == "" {
+		return text
+	}
+	return "\x1b[" + sgr + "m" + text + "\x1b[0m"
+}
+
+// MarkdownRenderer writes the table as GitHub-flavoured markdown table.
+type MarkdownRenderer struct{}
+
+// Render implements Renderer.
+func (r MarkdownRenderer) Render(w io.Writer, t *Table) error {
+	var sb strings.Builder
+	headers := t.Headers()
+	fmt.Fprintf(&sb, "| %s |\n", strings.Join(escapeMarkdown(headers), " | "))
+	aligns := make([]string, 0, len(headers))
+	for _, c := range t.visible() {
+		if t.Columns[c].LeftAlign {
+			aligns = append(aligns, ":---")
+		} else {
+			aligns = append(aligns, "---:")
+		}
+	}
+	fmt.Fprintf(&sb, "| %s |\n", strings.Join(aligns, " | "))
+	for _, row := range t.Rows() {
+		fmt.Fprintf(&sb, "| %s |\n", strings.Join(escapeMarkdown(row), " | "))
+	}
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("table.MarkdownRenderer: failed to write table %w", err)
+	}
+	return nil
+}
+
+// escapeMarkdown escapes the characters that would break a markdown table cell.
+func escapeMarkdown(values []string) []string {
+	r := strings.NewReplacer("|", "\\|", "\n", " ")
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = r.Replace(v)
+	}
+	return escaped
+}
+
+// fit computes the width of the visible columns: wide enough for the header and every value, at least the width
+// of the column. If the table is wider than maxWidth including the decoration, the widest columns are shrunk.
+func (t *Table) fit(columns []int, maxWidth, decoration int) []int {
+	widths := make([]int, len(columns))
+	total := decoration
+	for i, c := range columns {
+		width := t.Columns[c].Width
+		if n := utf8.RuneCountInString(t.Columns[c].Header); n > width {
+			width = n
+		}
+		for _, row := range t.Data {
+			if n := utf8.RuneCountInString(row[c]); n > width {
+				width = n
+			}
+		}
+		widths[i] = width
+		total += width
+	}
+
+	for maxWidth > 0 && total > maxWidth {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// cellLines lays the visible cells of a row out into one or more lines, depending on the overflow.
+func cellLines(row Row, columns []int, widths []int, overflow Overflow) [][]string {
+	lines := [][]string{make([]string, len(columns))}
+	for i, c := range columns {
+		value := []rune(row[c])
+		if len(value) <= widths[i] {
+			lines[0][i] = string(value)
+			continue
+		}
+		if overflow != OverflowWrap {
+			lines[0][i] = string(value[:widths[i]-1]) + "…"
+			continue
+		}
+		for l := 0; len(value) > 0; l++ {
+			if l == len(lines) {
+				lines = append(lines, make([]string, len(columns)))
+			}
+			n := widths[i]
+			if n > len(value) {
+				n = len(value)
+			}
+			lines[l][i] = string(value[:n])
+			value = value[n:]
+		}
+	}
+	return lines
+}
+
+func pad(value string, width int, left bool) string {
+	padding := strings.Repeat(" ", width-utf8.RuneCountInString(value))
+	if left {
+		return value + padding
+	}
+	return padding + value
+}
+
+func (t *Table) headerRow() Row {
+	row := make(Row, len(t.Columns))
+	for c, col := range t.Columns {
+		row[c] = col.Header
+	}
+	return row
+}
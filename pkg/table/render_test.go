@@ -0,0 +1,121 @@
+package table
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTable() Table {
+	t := NewTable([]Column{
+		NewColumn("HOSTNAME").WithLeftAlign(),
+		NewColumn("RUNS").WithType(TypeInt),
+		NewColumn("MAX").WithType(TypeFloat),
+		{Header: "SECRET", Hide: true},
+	}, []Row{})
+	t.Append(Row{"host_000002", "10", "12.5000ms", "x"}, []Value{"host_000002", 10, 12.5, "x"})
+	t.Append(Row{"host_000001", "9", "-", "y"}, nil)
+	t.Sort = SortBy(false, 0)
+	return t
+}
+
+func render(t *testing.T, r Renderer) string {
+	tbl := newTestTable()
+	var buf bytes.Buffer
+	require.NoError(t, r.Render(&buf, &tbl))
+	return buf.String()
+}
+
+func TestTextRenderer(t *testing.T) {
+	assert.Equal(t, ""+
+		"HOSTNAME     RUNS        MAX  \n"+
+		"host_000001     9          -  \n"+
+		"host_000002    10  12.5000ms  \n",
+		render(t, TextRenderer{}))
+
+	assert.Equal(t, ""+
+		"HOSTNAME  RUNS        MAX  \n"+
+		"host_00…     9          -  \n"+
+		"host_00…    10  12.5000ms  \n",
+		render(t, TextRenderer{MaxWidth: 27}), "the widest column is shrunk")
+
+	assert.Equal(t, ""+
+		"HOSTNAME  RUNS        MAX  \n"+
+		"host_000     9          -  \n"+
+		"001                        \n"+
+		"host_000    10  12.5000ms  \n"+
+		"002                        \n",
+		render(t, TextRenderer{MaxWidth: 27, Overflow: OverflowWrap}))
+}
+
+func TestBoxRenderer(t *testing.T) {
+	assert.Equal(t, ""+
+		"┌─────────────┬──────┬───────────┐\n"+
+		"│ HOSTNAME    │ RUNS │       MAX │\n"+
+		"├─────────────┼──────┼───────────┤\n"+
+		"│ host_000001 │    9 │         - │\n"+
+		"│ host_000002 │   10 │ 12.5000ms │\n"+
+		"└─────────────┴──────┴───────────┘\n",
+		render(t, BoxRenderer{}))
+}
+
+func TestRendererStyle(t *testing.T) {
+	tbl := newTestTable()
+	tbl.Style = func(c Cell) string {
+		if v, ok := c.Raw.(float64); ok && v > 10 {
+			return "31"
+		}
+		return ""
+	}
+	var buf bytes.Buffer
+	require.NoError(t, TextRenderer{}.Render(&buf, &tbl))
+	assert.NotContains(t, buf.String(), "\x1b[", "styles are only applied with Color")
+
+	buf.Reset()
+	require.NoError(t, TextRenderer{Color: true}.Render(&buf, &tbl))
+	assert.Equal(t, ""+
+		"HOSTNAME     RUNS        MAX  \n"+
+		"host_000001     9          -  \n"+
+		"host_000002    10  \x1b[31m12.5000ms\x1b[0m  \n",
+		buf.String())
+}
+
+func TestJSONDuration(t *testing.T) {
+	tbl := NewTable([]Column{NewColumn("MAX").WithType(TypeDuration)}, []Row{})
+	tbl.Append(Row{"1.500ms"}, []Value{1500 * time.Microsecond})
+	tbl.Append(Row{"2.000s"}, nil)
+	b, err := tbl.JSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"MAX":1.5},{"MAX":2000}]`, string(b))
+}
+
+func TestDelimitedRenderer(t *testing.T) {
+	assert.Equal(t, "HOSTNAME,RUNS,MAX\nhost_000001,9,-\nhost_000002,10,12.5000ms\n", render(t, DelimitedRenderer{Comma: ','}))
+	assert.Equal(t, "HOSTNAME\tRUNS\tMAX\nhost_000001\t9\t-\nhost_000002\t10\t12.5000ms\n",
+		render(t, DelimitedRenderer{Comma: '\t'}))
+}
+
+func TestJSONRenderer(t *testing.T) {
+	assert.Equal(t,
+		`[{"HOSTNAME":"host_000001","RUNS":9,"MAX":"-"},{"HOSTNAME":"host_000002","RUNS":10,"MAX":12.5}]`+"\n",
+		render(t, JSONRenderer{}))
+}
+
+func TestNewRenderer(t *testing.T) {
+	for _, format := range Formats {
+		r, err := NewRenderer(format, Options{MaxWidth: 80})
+		require.NoError(t, err, format)
+		assert.NotEmpty(t, render(t, r), format)
+	}
+	_, err := NewRenderer("yaml", Options{})
+	assert.ErrorContains(t, err, "unknown format 'yaml'")
+}
+
+func TestHideColumns(t *testing.T) {
+	tbl := newTestTable()
+	tbl.HideColumns("max", "runs")
+	assert.Equal(t, []string{"HOSTNAME"}, tbl.Headers())
+}
@@ -1,7 +1,6 @@
 package table
 
 import (
-	"fmt"
 	"io"
 	"sort"
 	"strings"
@@ -10,24 +9,56 @@ import (
 type (
 	// Table represents a table of data to be rendered.
 	Table struct {
-		Columns       []Column
-		Data          []Row
-		Sort          []int
+		Columns []Column
+		Data    []Row
+		// Raw holds the raw values behind the formatted Data, row by row. It is optional, typed columns without raw
+		// values are sorted by parsing their text.
+		Raw           [][]Value
+		Sort          []SortKey
 		ColumnSpacing string
+		// Style returns the ANSI SGR parameters a cell is highlighted with on colour terminals, e.g. "31" for red,
+		// or an empty string to leave it plain.
+		Style StyleFunc
 	}
 
+	// Cell describes a cell to be styled.
+	Cell struct {
+		Column Column
+		Index  int
+		Text   string
+		// Raw is the raw value behind the text, nil if unknown.
+		Raw Value
+		// Row and RawRow are all values of the row of the cell.
+		Row    Row
+		RawRow []Value
+	}
+
+	// StyleFunc returns the style of a cell, see Table.Style.
+	StyleFunc func(Cell) string
+
 	// Row is a single row of data in a table.
 	Row = []string
 
+	// Value is the raw value of a cell, e.g. an int, float64 or time.Duration.
+	Value = interface{}
+
 	// Column represents metadata about a column in a table.
 	Column struct {
 		Header string
 		Width  int
-		// If false, render this column.
+		// If true, do not render this column.
 		Hide bool
 		// If true, set the width to the widest value in this column.
 		Flexible  bool
 		LeftAlign bool
+		// Type controls how the column is compared when sorting.
+		Type Type
+	}
+
+	// SortKey sorts the rows by a column, ascending unless Desc is set.
+	SortKey struct {
+		Column int
+		Desc   bool
 	}
 )
 
@@ -38,11 +69,29 @@ func NewTable(cols []Column, data []Row) Table {
 	return Table{
 		Columns:       cols,
 		Data:          data,
-		Sort:          []int{},
+		Sort:          []SortKey{},
 		ColumnSpacing: defaultColumnSpacing,
 	}
 }
 
+// Append adds a row with the raw values behind its formatted cells.
+func (t *Table) Append(row Row, raw []Value) {
+	for len(t.Raw) < len(t.Data) {
+		t.Raw = append(t.Raw, nil)
+	}
+	t.Data = append(t.Data, row)
+	t.Raw = append(t.Raw, raw)
+}
+
+// SortBy returns sort keys in the given direction for the column indexes.
+func SortBy(desc bool, columns ...int) []SortKey {
+	keys := make([]SortKey, 0, len(columns))
+	for _, c := range columns {
+		keys = append(keys, SortKey{Column: c, Desc: desc})
+	}
+	return keys
+}
+
 // NewColumn creates a new flexible column with the given name.
 func NewColumn(header string) Column {
 	return Column{
@@ -52,77 +101,143 @@ func NewColumn(header string) Column {
 	}
 }
 
+// WithType sets the value type of this column and returns it.
+func (c Column) WithType(typ Type) Column {
+	c.Type = typ
+	return c
+}
+
 // WithLeftAlign turns on the left align of this column and returns it.
 func (c Column) WithLeftAlign() Column {
 	c.LeftAlign = true
 	return c
 }
 
-// Render writes the full table to the given Writer.
+// Render writes the full table as space-padded text to the given Writer.
 func (t *Table) Render(w io.Writer) {
-	columnWidths := t.columnWidths()
-	t.renderRow(w, t.headerRow(), columnWidths)
+	_ = TextRenderer{}.Render(w, t)
+}
+
+// HideColumns hides the columns with the given headers, matched case-insensitively.
+func (t *Table) HideColumns(headers ...string) {
+	for c, col := range t.Columns {
+		for _, h := range headers {
+			if strings.EqualFold(col.Header, h) {
+				t.Columns[c].Hide = true
+			}
+		}
+	}
+}
+
+// Headers returns the headers of all visible columns.
+func (t *Table) Headers() []string {
+	var headers []string
+	for _, col := range t.Columns {
+		if !col.Hide {
+			headers = append(headers, col.Header)
+		}
+	}
+	return headers
+}
+
+// Rows returns the sorted data of all visible columns.
+func (t *Table) Rows() []Row {
 	t.sort()
+	rows := make([]Row, 0, len(t.Data))
 	for _, row := range t.Data {
-		t.renderRow(w, row, columnWidths)
+		var visible Row
+		for c, col := range t.Columns {
+			if !col.Hide {
+				visible = append(visible, row[c])
+			}
+		}
+		rows = append(rows, visible)
 	}
+	return rows
 }
 
-func (t *Table) columnWidths() []int {
-	widths := make([]int, len(t.Columns))
+// RenderMarkdown writes the full table as GitHub-flavoured markdown table.
+func (t *Table) RenderMarkdown(w io.Writer) {
+	_ = MarkdownRenderer{}.Render(w, t)
+}
+
+// visible returns the indexes of all visible columns.
+func (t *Table) visible() []int {
+	var columns []int
 	for c, col := range t.Columns {
-		width := col.Width
-		if col.Flexible {
-			for _, row := range t.Data {
-				if len(row[c]) > width {
-					width = len(row[c])
-				}
-			}
+		if !col.Hide {
+			columns = append(columns, c)
 		}
-		widths[c] = width
 	}
-	return widths
+	return columns
 }
 
 func (t *Table) sort() {
 	if len(t.Sort) == 0 {
 		return
 	}
-	sort.Slice(t.Data, func(i, j int) bool {
-		for _, sortCol := range t.Sort {
-			if t.Data[i][sortCol] < t.Data[j][sortCol] {
-				return true
-			} else if t.Data[i][sortCol] > t.Data[j][sortCol] {
-				return false
+	order := make([]int, len(t.Data))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		for _, key := range t.Sort {
+			if c := t.compare(order[i], order[j], key); c != 0 {
+				return c < 0
 			}
 		}
 		return false
 	})
-}
 
-func (t *Table) renderRow(w io.Writer, row Row, columnWidths []int) {
-	for c, col := range t.Columns {
-		if col.Hide {
-			continue
-		}
-		value := row[c]
-		if len(value) > columnWidths[c] {
-			value = value[:columnWidths[c]]
-		}
-		padding := strings.Repeat(" ", columnWidths[c]-len(value))
-		if col.LeftAlign {
-			fmt.Fprintf(w, "%s%s%s", value, padding, t.ColumnSpacing)
-		} else {
-			fmt.Fprintf(w, "%s%s%s", padding, value, t.ColumnSpacing)
+	data := make([]Row, len(t.Data))
+	var raw [][]Value
+	if len(t.Raw) > 0 {
+		raw = make([][]Value, len(t.Data))
+	}
+	for i, o := range order {
+		data[i] = t.Data[o]
+		if raw != nil && o < len(t.Raw) {
+			raw[i] = t.Raw[o]
 		}
 	}
-	fmt.Fprint(w, "\n")
+	t.Data, t.Raw = data, raw
 }
 
-func (t *Table) headerRow() Row {
-	row := make(Row, len(t.Columns))
-	for c, col := range t.Columns {
-		row[c] = col.Header
+// compare compares the cells of the sort key column in two rows according to the type of the column. Cells of
+// typed columns without a value, such as "-", sort after all values in either direction.
+func (t *Table) compare(i, j int, key SortKey) int {
+	column := key.Column
+	direction := 1
+	if key.Desc {
+		direction = -1
+	}
+	if t.Columns[column].Type == TypeString {
+		return direction * strings.Compare(t.Data[i][column], t.Data[j][column])
+	}
+	a, aOK := t.value(i, column)
+	b, bOK := t.value(j, column)
+	switch {
+	case !aOK && !bOK:
+		return direction * strings.Compare(t.Data[i][column], t.Data[j][column])
+	case !aOK:
+		return 1
+	case !bOK:
+		return -1
+	case a < b:
+		return -direction
+	case a > b:
+		return direction
+	default:
+		return 0
+	}
+}
+
+// value returns the numeric value of a cell, taken from the raw values or parsed from its text.
+func (t *Table) value(row, column int) (float64, bool) {
+	if row < len(t.Raw) && column < len(t.Raw[row]) {
+		if v, ok := number(t.Raw[row][column]); ok {
+			return v, true
+		}
 	}
-	return row
+	return t.Columns[column].Type.parse(t.Data[row][column])
 }
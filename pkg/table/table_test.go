@@ -0,0 +1,70 @@
+package table
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortTypedColumns(t *testing.T) {
+	tbl := NewTable([]Column{
+		NewColumn("HOST").WithLeftAlign(),
+		NewColumn("RUNS").WithType(TypeInt),
+		NewColumn("MAX").WithType(TypeFloat),
+		NewColumn("DUR").WithType(TypeDuration),
+	}, []Row{})
+	tbl.Append(Row{"a", "9", "9.8000ms", "900ms"}, []Value{"a", 9, 9.8, 900 * time.Millisecond})
+	tbl.Append(Row{"b", "10", "10.2000ms", "1.5s"}, []Value{"b", 10, 10.2, 1500 * time.Millisecond})
+	tbl.Append(Row{"c", "100", "-", "2m"}, nil)
+
+	tbl.Sort = SortBy(false, 1)
+	assert.Equal(t, []string{"a", "b", "c"}, column(tbl.Rows(), 0))
+
+	tbl.Sort = SortBy(true, 2)
+	assert.Equal(t, []string{"b", "a", "c"}, column(tbl.Rows(), 0), "cells without a value sort last")
+
+	tbl.Sort = SortBy(true, 3)
+	assert.Equal(t, []string{"c", "b", "a"}, column(tbl.Rows(), 0), "durations are parsed without raw values")
+	assert.Equal(t, "b", tbl.Raw[1][0], "raw values are sorted with their rows")
+}
+
+func TestSortMultipleKeys(t *testing.T) {
+	tbl := NewTable([]Column{NewColumn("HOST"), NewColumn("WORKER").WithType(TypeInt)}, []Row{
+		{"b", "2"}, {"a", "10"}, {"b", "10"}, {"a", "2"},
+	})
+	tbl.Sort = []SortKey{{Column: 0}, {Column: 1, Desc: true}}
+	assert.Equal(t, []Row{{"a", "10"}, {"a", "2"}, {"b", "10"}, {"b", "2"}}, tbl.Rows())
+}
+
+func TestParseSort(t *testing.T) {
+	columns := []Column{NewColumn("HOSTNAME"), NewColumn("TOTAL_RUN"), NewColumn("MAX")}
+
+	keys, err := ParseSort("max:desc, total-run,hostname:ASC", columns)
+	require.NoError(t, err)
+	assert.Equal(t, []SortKey{{Column: 2, Desc: true}, {Column: 1}, {Column: 0}}, keys)
+
+	_, err = ParseSort("min", columns)
+	assert.ErrorContains(t, err, "unknown column 'min'")
+
+	_, err = ParseSort("max:down", columns)
+	assert.ErrorContains(t, err, "invalid direction")
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tbl := NewTable([]Column{NewColumn("HOST").WithLeftAlign(), NewColumn("MAX"), {Header: "HIDDEN", Hide: true}},
+		[]Row{{"a|b", "1ms", "x"}})
+	var buf bytes.Buffer
+	tbl.RenderMarkdown(&buf)
+	assert.Equal(t, "| HOST | MAX |\n| :--- | ---: |\n| a\\|b | 1ms |\n", buf.String())
+}
+
+func column(rows []Row, c int) []string {
+	values := make([]string, 0, len(rows))
+	for _, r := range rows {
+		values = append(values, r[c])
+	}
+	return values
+}
@@ -0,0 +1,117 @@
+package table
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the type of the values of a column.
+type Type int
+
+const (
+	// TypeString columns are compared lexicographically.
+	TypeString Type = iota
+	// TypeInt columns hold integers.
+	TypeInt
+	// TypeFloat columns hold floating point numbers, optionally followed by a unit such as "ms" or "%".
+	TypeFloat
+	// TypeDuration columns hold durations in the format of time.Duration.
+	TypeDuration
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeDuration:
+		return "duration"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// parse reads the numeric value of a formatted cell.
+func (t Type) parse(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	switch t {
+	case TypeInt, TypeFloat:
+		end := 0
+		for end < len(text) && strings.ContainsRune("+-.0123456789eE", rune(text[end])) {
+			end++
+		}
+		v, err := strconv.ParseFloat(text[:end], 64)
+		return v, err == nil
+	case TypeDuration:
+		d, err := time.ParseDuration(text)
+		return float64(d), err == nil
+	default:
+		return 0, false
+	}
+}
+
+// number converts a raw value to float64 for comparison.
+func number(v Value) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case time.Duration:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// ParseSort parses a comma separated list of sort keys in the form header[:asc|desc], e.g. "max:desc,hostname".
+// Headers are matched case-insensitively, dashes match underscores.
+func ParseSort(spec string, columns []Column) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, dir := part, "asc"
+		if i := strings.LastIndex(part, ":"); i >= 0 {
+			name, dir = part[:i], strings.ToLower(part[i+1:])
+		}
+		if dir != "asc" && dir != "desc" {
+			return nil, fmt.Errorf("table.ParseSort: invalid direction '%s' of '%s', expected asc or desc", dir, part)
+		}
+
+		column := -1
+		for i, c := range columns {
+			if strings.EqualFold(c.Header, strings.ReplaceAll(name, "-", "_")) {
+				column = i
+				break
+			}
+		}
+		if column < 0 {
+			headers := make([]string, 0, len(columns))
+			for _, c := range columns {
+				headers = append(headers, strings.ToLower(c.Header))
+			}
+			return nil, fmt.Errorf("table.ParseSort: unknown column '%s', possible values are %s", name,
+				strings.Join(headers, ","))
+		}
+		keys = append(keys, SortKey{Column: column, Desc: dir == "desc"})
+	}
+	return keys, nil
+}